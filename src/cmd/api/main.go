@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -12,17 +13,22 @@ import (
 	"exchange-rates-service/src/internal/storage"
 	"log"
 	"net/http"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	_ "exchange-rates-service/src/docs"
 
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 type HttpHandler struct {
-	rateService *service.RateService
+	rateService      *service.RateService
+	currencyRegistry *service.CurrencyRegistry
 }
 
 // StartUpdateRate godoc
@@ -52,7 +58,7 @@ func (h *HttpHandler) startUpdateRate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updateId, err := h.rateService.StartUpdateRate(request.From, request.To)
+	updateId, err := h.rateService.StartUpdateRate(r.Context(), request.From, request.To)
 	if err != nil {
 		handleError(w, err)
 		return
@@ -69,17 +75,73 @@ func (h *HttpHandler) startUpdateRate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// StartUpdateRateBatch godoc
+//
+//	@Summary		Start exchange rate updates in batch
+//	@Description	Start exchange rate updates for the list of pairs and/or for the base currency to each of the targets. Returns updateId or error for each pair, the key is from/to
+//	@Tags			exchange-rate-api
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.StartUpdateRateBatchRequest	true	"Batch update request"
+//	@Success		200		{object}	model.StartUpdateRateBatchResponse	"OK"
+//	@Failure		400		{string}	error								"BadRequest"
+//	@Router			/api/rates/v1/update/start:batch [post]
+func (h *HttpHandler) startUpdateRateBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var request model.StartUpdateRateBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	results, err := h.rateService.StartUpdateRates(r.Context(), request.CurrencyPairs())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response := model.StartUpdateRateBatchResponse{
+		Updates: make(map[string]model.StartUpdateRateBatchItem, len(results)),
+	}
+	for pair, result := range results {
+		item := model.StartUpdateRateBatchItem{}
+		if result.Error != nil {
+			errorMessage := result.Error.Error()
+			item.Error = &errorMessage
+		} else {
+			updateId := result.UpdateId
+			item.UpdateId = &updateId
+		}
+		response.Updates[pair.String()] = item
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
 // GetUpdateRate godoc
 //
 //	@Summary		Get exchange rate update
-//	@Description	Get the exchange rate update by updateId. You can retrieve updateId in StartUpdateRate method. Returns rate and updateTime. Both will be null if the update was not performed
+//	@Description	Get the exchange rate update by updateId. You can retrieve updateId in StartUpdateRate method. Returns status (updating, done or error), rate and updateTime. Rate and updateTime will be null if the update was not performed, errorMessage contains the reason of the failed update. Provider is the rate provider which served the rate
 //	@Tags			exchange-rate-api
 //	@Accept			json
 //	@Produce		json
-//	@Param			updateId	query		string					true	"Update id"
-//	@Success		200			{object}	model.GetRateResponse	"OK"
-//	@Failure		404			{string}	error					"NotFound"
-//	@Failure		400			{string}	error					"BadRequest"
+//	@Param			updateId	query		string						true	"Update id"
+//	@Success		200			{object}	model.GetRateUpdateResponse	"OK"
+//	@Failure		404			{string}	error						"NotFound"
+//	@Failure		400			{string}	error						"BadRequest"
 //	@Router			/api/rates/v1/update [get]
 func (h *HttpHandler) getUpdateRate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
@@ -93,24 +155,25 @@ func (h *HttpHandler) getUpdateRate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	update, err := h.rateService.GetRateUpdate(updateId)
+	update, err := h.rateService.GetRateUpdate(r.Context(), updateId)
 	if err != nil {
 		handleError(w, err)
 		return
 	}
 
-	if update.UpdateDateTime == nil {
-		if err = json.NewEncoder(w).Encode(model.GetRateResponse{}); err != nil {
-			handleError(w, err)
-		}
-		return
+	rate := newGetRateResponse(update.ExchangeRate)
+	response := model.GetRateUpdateResponse{
+		Status:       update.Status.String(),
+		Attempts:     update.Attempts,
+		ErrorMessage: update.ErrorMessage,
+		Rate:         rate.Rate,
+		UpdateTime:   rate.UpdateTime,
+		Provider:     rate.Provider,
 	}
 
-	rateValue := update.Rate.String()
-	updateValue := update.UpdateDateTime.Format(time.RFC3339Nano)
-	response := model.GetRateResponse{
-		Rate:       &rateValue,
-		UpdateTime: &updateValue,
+	if update.CreatedAt != nil {
+		createdAt := update.CreatedAt.Format(time.RFC3339Nano)
+		response.CreatedAt = &createdAt
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -123,12 +186,13 @@ func (h *HttpHandler) getUpdateRate(w http.ResponseWriter, r *http.Request) {
 // GetLastUpdateRate godoc
 //
 //	@Summary		Get last exchange rate update
-//	@Description	Get exchange rate update started by StartUpdateRate method. Returns rate and updateTime
+//	@Description	Get exchange rate update started by StartUpdateRate method. Returns rate and updateTime. If the pair was never updated, the rate is derived from the opposite direction (derived is inverse) or built from the other stored rates (derived is cross). Legs contain the rates used, updateTime is the oldest leg time. Provider is the rate provider which served the stored rate
 //	@Tags			exchange-rate-api
 //	@Accept			json
 //	@Produce		json
 //	@Param			from	query		string					true	"From currency"
 //	@Param			to		query		string					true	"To currency"
+//	@Param			derived	query		bool					false	"Allow inverse and cross rates when the pair is not stored, true by default"
 //	@Success		200		{object}	model.GetRateResponse	"OK"
 //	@Failure		404		{string}	error					"NotFound"
 //	@Failure		400		{string}	error					"BadRequest"
@@ -152,25 +216,248 @@ func (h *HttpHandler) getLastUpdateRate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	rate, err := h.rateService.GetLastRate(from, to)
+	allowDerived := true
+	if derivedValue := r.URL.Query().Get("derived"); derivedValue != "" {
+		var err error
+		if allowDerived, err = strconv.ParseBool(derivedValue); err != nil {
+			handleError(w, internal.NewBadRequestError("derived is not a boolean"))
+			return
+		}
+	}
+
+	rate, err := h.rateService.GetLastRate(r.Context(), from, to, allowDerived)
 
 	if err != nil {
 		handleError(w, err)
 		return
 	}
 
-	if rate.UpdateDateTime == nil {
-		if err = json.NewEncoder(w).Encode(model.GetRateResponse{}); err != nil {
-			handleError(w, err)
+	response := newGetRateResponse(rate)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// GetLastRateBatch godoc
+//
+//	@Summary		Get last exchange rates in batch
+//	@Description	Get stored rates of the pairs in one request. Rates are returned in the requested order, found is false when the pair has no stored rate. Rates are not derived from other pairs
+//	@Tags			exchange-rate-api
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.GetLastRateBatchRequest	true	"Pairs"
+//	@Success		200		{object}	model.GetLastRateBatchResponse	"OK"
+//	@Failure		400		{string}	error							"BadRequest"
+//	@Router			/api/rates/v1/rates/last:batch [post]
+func (h *HttpHandler) getLastRateBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var request model.GetLastRateBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	results, err := h.rateService.GetLastRates(r.Context(), request.CurrencyPairs())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response := model.GetLastRateBatchResponse{
+		Rates: make([]model.LastRateBatchItem, 0, len(results)),
+	}
+	for _, result := range results {
+		item := model.LastRateBatchItem{
+			From: result.Pair.From,
+			To:   result.Pair.To,
+		}
+
+		if result.Error != nil {
+			errorMessage := result.Error.Error()
+			item.Error = &errorMessage
+		} else if result.Rate.UpdateDateTime != nil {
+			rate := newGetRateResponse(result.Rate)
+			item.Found = true
+			item.Rate = rate.Rate
+			item.UpdateTime = rate.UpdateTime
+			item.Provider = rate.Provider
 		}
+
+		response.Rates = append(response.Rates, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		handleError(w, err)
 		return
 	}
+}
 
-	rateValue := rate.Rate.String()
-	updateValue := rate.UpdateDateTime.Format(time.RFC3339Nano)
-	response := model.GetRateResponse{
-		Rate:       &rateValue,
-		UpdateTime: &updateValue,
+// Convert godoc
+//
+//	@Summary		Convert amount
+//	@Description	Convert amount using the last exchange rate. The result is rounded to the minor units of the to currency. Returns the rate and its updateTime used for the conversion
+//	@Tags			exchange-rate-api
+//	@Accept			json
+//	@Produce		json
+//	@Param			from		query		string					true	"From currency"
+//	@Param			to			query		string					true	"To currency"
+//	@Param			amount		query		string					true	"Amount in from currency"
+//	@Param			rounding	query		string					false	"Rounding mode: half-even, half-up or truncate. Service default is used if not set"
+//	@Success		200			{object}	model.ConvertResponse	"OK"
+//	@Failure		404			{string}	error					"NotFound"
+//	@Failure		400			{string}	error					"BadRequest"
+//	@Router			/api/rates/v1/convert [get]
+func (h *HttpHandler) convert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	from := query.Get("from")
+	to := query.Get("to")
+	amountValue := query.Get("amount")
+
+	if from == "" {
+		handleError(w, internal.NewBadRequestError("from currency is not set"))
+		return
+	}
+
+	if to == "" {
+		handleError(w, internal.NewBadRequestError("to currency is not set"))
+		return
+	}
+
+	if amountValue == "" {
+		handleError(w, internal.NewBadRequestError("amount is not set"))
+		return
+	}
+
+	amount, err := decimal.NewFromString(amountValue)
+	if err != nil {
+		handleError(w, internal.NewBadRequestError("amount is not a number"))
+		return
+	}
+
+	var roundingMode model.RoundingMode
+	if roundingValue := query.Get("rounding"); roundingValue != "" {
+		if roundingMode, err = model.ParseRoundingMode(roundingValue); err != nil {
+			handleError(w, internal.NewBadRequestError(err.Error()))
+			return
+		}
+	}
+
+	conversion, err := h.rateService.Convert(r.Context(), from, to, amount, roundingMode)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response := model.ConvertResponse{
+		From:         from,
+		To:           to,
+		Amount:       conversion.Amount.String(),
+		Result:       conversion.Result.StringFixed(conversion.MinorUnits),
+		RoundingMode: string(conversion.RoundingMode),
+		Rate:         conversion.Rate.Rate.String(),
+		UpdateTime:   conversion.Rate.UpdateDateTime.Format(time.RFC3339Nano),
+		Provider:     newProviderResponse(conversion.Rate.Provider),
+		Derived:      newDerivedResponse(conversion.Rate.Derived),
+		Legs:         newRateLegResponses(conversion.Rate.Legs),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// GetRateHistory godoc
+//
+//	@Summary		Get exchange rate history
+//	@Description	Get rates stored for the currency pair ordered by updateTime. Use nextCursor from the response to get the next page, it is null on the last page
+//	@Tags			exchange-rate-api
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string							true	"From currency"
+//	@Param			to		query		string							true	"To currency"
+//	@Param			since	query		string							false	"Start of the range in RFC3339 format, inclusive"
+//	@Param			until	query		string							false	"End of the range in RFC3339 format, exclusive"
+//	@Param			cursor	query		string							false	"Cursor returned by the previous page"
+//	@Param			limit	query		int								false	"Page size, 100 by default, 1000 max"
+//	@Success		200		{object}	model.GetRateHistoryResponse	"OK"
+//	@Failure		400		{string}	error							"BadRequest"
+//	@Router			/api/rates/v1/history [get]
+func (h *HttpHandler) getRateHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	from := query.Get("from")
+	to := query.Get("to")
+
+	if from == "" {
+		handleError(w, internal.NewBadRequestError("from currency is not set"))
+		return
+	}
+
+	if to == "" {
+		handleError(w, internal.NewBadRequestError("to currency is not set"))
+		return
+	}
+
+	since, err := parseTimeParameter(query.Get("since"))
+	if err != nil {
+		handleError(w, internal.NewBadRequestError("since is not a valid RFC3339 time"))
+		return
+	}
+
+	until, err := parseTimeParameter(query.Get("until"))
+	if err != nil {
+		handleError(w, internal.NewBadRequestError("until is not a valid RFC3339 time"))
+		return
+	}
+
+	limit := 0
+	if limitValue := query.Get("limit"); limitValue != "" {
+		if limit, err = strconv.Atoi(limitValue); err != nil {
+			handleError(w, internal.NewBadRequestError("limit is not a number"))
+			return
+		}
+	}
+
+	page, err := h.rateService.GetRateHistory(r.Context(), from, to, since, until, query.Get("cursor"), limit)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response := model.GetRateHistoryResponse{
+		Rates: make([]model.GetRateResponse, 0, len(page.Rates)),
+	}
+	for _, rate := range page.Rates {
+		response.Rates = append(response.Rates, newGetRateResponse(rate))
+	}
+
+	if page.NextCursor != nil {
+		nextCursor := service.EncodeHistoryCursor(*page.NextCursor)
+		response.NextCursor = &nextCursor
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -180,6 +467,198 @@ func (h *HttpHandler) getLastUpdateRate(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// GetCurrencies godoc
+//
+//	@Summary		Get supported currencies
+//	@Description	Get currencies which can be used in exchange rate methods. For each currency returns the pairs from it that have a stored rate with their last updateTime
+//	@Tags			exchange-rate-api
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	model.GetCurrenciesResponse	"OK"
+//	@Router			/api/rates/v1/currencies [get]
+func (h *HttpHandler) getCurrencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.NotFound(w, r)
+		return
+	}
+
+	currencies, err := h.rateService.GetCurrencies(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response := model.GetCurrenciesResponse{
+		Currencies: make([]model.CurrencyRatesResponse, 0, len(currencies)),
+	}
+	for _, currency := range currencies {
+		pairs := make([]model.CurrencyPairResponse, 0, len(currency.Rates))
+		for _, rate := range currency.Rates {
+			pairs = append(pairs, model.CurrencyPairResponse{
+				To:         rate.ToCurrency,
+				UpdateTime: rate.UpdateTime.Format(time.RFC3339Nano),
+			})
+		}
+
+		response.Currencies = append(response.Currencies, model.CurrencyRatesResponse{
+			Code:        currency.Currency.Code,
+			NumericCode: currency.Currency.NumericCode,
+			MinorUnits:  currency.Currency.MinorUnits,
+			Name:        currency.Currency.Name,
+			Pairs:       pairs,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// EnableCurrency godoc
+//
+//	@Summary		Enable currency
+//	@Description	Enable currency, so it can be used in exchange rate methods.
+//	@Description	Served only on the admin address API_ADMIN_ADDRESS, not on the public port
+//	@Tags			admin-api
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.SetCurrencyActiveRequest	true	"Currency"
+//	@Success		200		{object}	model.CurrencyResponse			"OK"
+//	@Failure		404		{string}	error							"NotFound"
+//	@Failure		400		{string}	error							"BadRequest"
+//	@Router			/api/admin/v1/currencies/enable [post]
+func (h *HttpHandler) enableCurrency(w http.ResponseWriter, r *http.Request) {
+	h.setCurrencyActive(w, r, true)
+}
+
+// DisableCurrency godoc
+//
+//	@Summary		Disable currency
+//	@Description	Disable currency. Exchange rate methods will return BadRequest for it. Stored rates are kept.
+//	@Description	Served only on the admin address API_ADMIN_ADDRESS, not on the public port
+//	@Tags			admin-api
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.SetCurrencyActiveRequest	true	"Currency"
+//	@Success		200		{object}	model.CurrencyResponse			"OK"
+//	@Failure		404		{string}	error							"NotFound"
+//	@Failure		400		{string}	error							"BadRequest"
+//	@Router			/api/admin/v1/currencies/disable [post]
+func (h *HttpHandler) disableCurrency(w http.ResponseWriter, r *http.Request) {
+	h.setCurrencyActive(w, r, false)
+}
+
+func (h *HttpHandler) setCurrencyActive(w http.ResponseWriter, r *http.Request, active bool) {
+	if r.Method != "POST" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var request model.SetCurrencyActiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currency, err := h.currencyRegistry.SetActive(r.Context(), request.Code, active)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response := newCurrencyResponse(currency)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+func newCurrencyResponse(currency model.Currency) model.CurrencyResponse {
+	return model.CurrencyResponse{
+		Code:        currency.Code,
+		NumericCode: currency.NumericCode,
+		MinorUnits:  currency.MinorUnits,
+		Name:        currency.Name,
+		Active:      currency.Active,
+	}
+}
+
+func newGetRateResponse(rate model.ExchangeRate) model.GetRateResponse {
+	if rate.UpdateDateTime == nil {
+		return model.GetRateResponse{}
+	}
+
+	rateValue := rate.Rate.String()
+	updateValue := rate.UpdateDateTime.Format(time.RFC3339Nano)
+	return model.GetRateResponse{
+		Rate:       &rateValue,
+		UpdateTime: &updateValue,
+		Provider:   newProviderResponse(rate.Provider),
+		Derived:    newDerivedResponse(rate.Derived),
+		Legs:       newRateLegResponses(rate.Legs),
+	}
+}
+
+func newProviderResponse(provider string) *string {
+	if provider == "" {
+		return nil
+	}
+
+	return &provider
+}
+
+func newDerivedResponse(derived model.RateDerivation) *string {
+	if derived == "" {
+		return nil
+	}
+
+	value := string(derived)
+	return &value
+}
+
+func newRateLegResponses(legs []model.ExchangeRateLeg) []model.RateLegResponse {
+	if len(legs) == 0 {
+		return nil
+	}
+
+	responses := make([]model.RateLegResponse, 0, len(legs))
+	for _, leg := range legs {
+		responses = append(responses, model.RateLegResponse{
+			From:       leg.FromCurrency,
+			To:         leg.ToCurrency,
+			Rate:       leg.Rate.String(),
+			UpdateTime: leg.UpdateDateTime.Format(time.RFC3339Nano),
+			Provider:   leg.Provider,
+			Inverted:   leg.Inverted,
+		})
+	}
+
+	return responses
+}
+
+func parseTimeParameter(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed = parsed.UTC()
+	return &parsed, nil
+}
+
 func handleError(w http.ResponseWriter, err error) {
 	serviceError := &internal.ServiceError{}
 	if errors.As(err, &serviceError) {
@@ -202,19 +681,64 @@ func main() {
 
 	exchangeRateStorage := storage.NewRateStorage(db)
 	exchangeRateUpdateStorage := storage.NewUpdateStorage(db)
-	repo := repository.NewExchangeRateRepository(db, exchangeRateStorage, exchangeRateUpdateStorage)
-	rateService := service.NewRateService(repo)
-	handler := HttpHandler{rateService: rateService}
+	exchangeRateHistoryStorage := storage.NewHistoryStorage(db)
+	repo := repository.NewExchangeRateRepository(db, exchangeRateStorage, exchangeRateUpdateStorage, exchangeRateHistoryStorage)
+	currencyRepo := repository.NewCurrencyRepository(storage.NewCurrencyStorage(db))
+	currencyRegistry := service.NewCurrencyRegistry(serviceConfig, currencyRepo)
+	rateService := service.NewRateService(serviceConfig, repo, currencyRegistry)
+	handler := HttpHandler{rateService: rateService, currencyRegistry: currencyRegistry}
 
 	http.HandleFunc("/api/rates/v1/update/start", handler.startUpdateRate)
+	http.HandleFunc("/api/rates/v1/update/start:batch", handler.startUpdateRateBatch)
 	http.HandleFunc("/api/rates/v1/update", handler.getUpdateRate)
 	http.HandleFunc("/api/rates/v1/update/last", handler.getLastUpdateRate)
+	http.HandleFunc("/api/rates/v1/rates/last:batch", handler.getLastRateBatch)
+	http.HandleFunc("/api/rates/v1/history", handler.getRateHistory)
+	http.HandleFunc("/api/rates/v1/convert", handler.convert)
+	http.HandleFunc("/api/rates/v1/currencies", handler.getCurrencies)
 
 	http.HandleFunc("/swagger/", httpSwagger.WrapHandler)
 
-	log.Println("Starting server at port 8080")
-	err = http.ListenAndServe(":8080", nil)
-	if err != nil {
-		log.Println("Error starting the server:", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// The request context gets the deadline, so storage queries of timed out requests are cancelled
+	servers := []*http.Server{{
+		Addr:    ":8080",
+		Handler: http.TimeoutHandler(http.DefaultServeMux, serviceConfig.ApiRequestTimeout, "Request timeout"),
+	}}
+
+	// Currencies are managed on the admin address only, so they cannot be changed through the public port
+	if serviceConfig.ApiAdminAddress != "" {
+		adminMux := http.NewServeMux()
+		adminMux.HandleFunc("/api/admin/v1/currencies/enable", handler.enableCurrency)
+		adminMux.HandleFunc("/api/admin/v1/currencies/disable", handler.disableCurrency)
+		servers = append(servers, &http.Server{
+			Addr:    serviceConfig.ApiAdminAddress,
+			Handler: http.TimeoutHandler(adminMux, serviceConfig.ApiRequestTimeout, "Request timeout"),
+		})
+	}
+
+	for _, server := range servers {
+		go func() {
+			log.Printf("Starting server at %s", server.Addr)
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Println("Error starting the server:", err)
+				stop()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+
+	// In-flight requests are drained within the grace period, the database is closed after them
+	log.Printf("Shutting down, in-flight requests have %s to finish", serviceConfig.ShutdownGracePeriod)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serviceConfig.ShutdownGracePeriod)
+	defer cancel()
+
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println("Error shutting down the server:", err)
+		}
 	}
 }
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"exchange-rates-service/src/config"
 	"exchange-rates-service/src/internal/integration"
+	"exchange-rates-service/src/internal/model"
 	"exchange-rates-service/src/internal/repository"
 	"exchange-rates-service/src/internal/service"
 	"exchange-rates-service/src/internal/storage"
 	"log"
+	"net/http"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -20,35 +28,171 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	defer db.Close()
 
 	exchangeRateStorage := storage.NewRateStorage(db)
 	exchangeRateUpdateStorage := storage.NewUpdateStorage(db)
-	repo := repository.NewExchangeRateRepository(db, exchangeRateStorage, exchangeRateUpdateStorage)
+	exchangeRateHistoryStorage := storage.NewHistoryStorage(db)
+	repo := repository.NewExchangeRateRepository(db, exchangeRateStorage, exchangeRateUpdateStorage, exchangeRateHistoryStorage)
 
-	var client integration.ExchangeRateApiClient
-	if serviceConfig.ExchangeIoApiKey != "" {
-		client = integration.NewExchangeRateApiIoClient(serviceConfig)
-	} else {
-		client = integration.NewCurrencyApiClient(serviceConfig)
+	usageRepo := repository.NewProviderUsageRepository(storage.NewProviderUsageStorage(db))
+	client, providerStatus, err := integration.NewExchangeRateApiClient(serviceConfig, usageRepo)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// ctx is done on the shutdown signal, no new updates are claimed after it. The current batch runs with workCtx,
+	// which is cancelled after the grace period, so the batch either finishes or releases its updates
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	context.AfterFunc(ctx, func() {
+		log.Printf("Shutting down, the current batch has %s to finish", serviceConfig.ShutdownGracePeriod)
+		time.AfterFunc(serviceConfig.ShutdownGracePeriod, cancelWork)
+	})
+
+	if serviceConfig.WorkerAdminAddress != "" {
+		go serveAdmin(ctx, serviceConfig, providerStatus)
 	}
 
 	rateServiceWorker := service.NewRateServiceWorker(serviceConfig, repo, client)
+	go reclaimStuckUpdates(ctx, serviceConfig, rateServiceWorker)
+
+	// Requested updates wake the worker at once, the ticker picks retries and updates missed by the listener
+	listener := storage.NewUpdateListener(serviceConfig.PostgresConnectionString)
+	defer listener.Close()
+
 	ticker := time.NewTicker(serviceConfig.WorkerTickInterval)
+	defer ticker.Stop()
+
+	rateServiceWorker.Run(ctx, workCtx, ticker.C, listener.Notifications())
+	log.Println("Worker stopped")
+}
+
+func reclaimStuckUpdates(ctx context.Context, serviceConfig *config.Config, rateServiceWorker *service.RateServiceWorker) {
+	ticker := time.NewTicker(serviceConfig.WorkerReaperInterval)
+	defer ticker.Stop()
+	requeuedTotal, failedTotal := 0, 0
 
 	for {
-		<-ticker.C
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 
-		for {
-			updated, err := rateServiceWorker.ExecuteUpdate()
-			if err != nil {
-				log.Println(err)
-			}
+		result, err := rateServiceWorker.ReclaimStuckUpdates(ctx)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		if result.Requeued == 0 && result.Failed == 0 {
+			continue
+		}
+
+		requeuedTotal += result.Requeued
+		failedTotal += result.Failed
+		log.Printf("Reclaimed stuck updates: %d requeued, %d failed (total %d requeued, %d failed)",
+			result.Requeued, result.Failed, requeuedTotal, failedTotal)
+	}
+}
+
+// serveAdmin exposes the worker state:
+// GET /api/admin/v1/circuit-breakers returns the circuit breaker of every provider,
+// GET /api/admin/v1/provider-quotas returns the monthly usage of providers with the quota,
+// GET /api/admin/v1/providers returns the registered providers with their capabilities.
+// The server is closed when the context is done
+func serveAdmin(ctx context.Context, serviceConfig *config.Config, providerStatus integration.ProviderStatus) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/v1/circuit-breakers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.NotFound(w, r)
+			return
+		}
 
-			if updated == 0 {
-				break
+		response := model.GetCircuitBreakersResponse{Breakers: make([]model.CircuitBreakerResponse, 0, len(providerStatus.Breakers))}
+		for _, status := range providerStatus.Breakers.Statuses() {
+			breaker := model.CircuitBreakerResponse{
+				Provider: status.Provider,
+				State:    string(status.State),
+				Failures: status.Failures,
+			}
+			if status.OpenedAt != nil {
+				openedAt := status.OpenedAt.UTC().Format(time.RFC3339Nano)
+				breaker.OpenedAt = &openedAt
 			}
+			response.Breakers = append(response.Breakers, breaker)
+		}
+
+		writeResponse(w, response)
+	})
+
+	mux.HandleFunc("/api/admin/v1/provider-quotas", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.NotFound(w, r)
+			return
+		}
+
+		quotas, err := providerStatus.Limiters.Quotas(r.Context())
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
 
-			log.Printf("Updated %d rates", updated)
+		response := model.GetProviderQuotasResponse{Quotas: make([]model.ProviderQuotaResponse, 0, len(quotas))}
+		for _, quota := range quotas {
+			response.Quotas = append(response.Quotas, model.ProviderQuotaResponse{
+				Provider:  quota.Provider,
+				Period:    quota.Period.Format("2006-01"),
+				Used:      quota.Used,
+				Limit:     quota.Limit,
+				Reserve:   quota.Reserve,
+				Remaining: quota.Remaining,
+			})
 		}
+
+		writeResponse(w, response)
+	})
+
+	mux.HandleFunc("/api/admin/v1/providers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.NotFound(w, r)
+			return
+		}
+
+		registrations := integration.RegisteredProviders()
+		response := model.GetProvidersResponse{Providers: make([]model.ProviderResponse, 0, len(registrations))}
+		for _, registration := range registrations {
+			response.Providers = append(response.Providers, model.ProviderResponse{
+				Name:        registration.Name,
+				Enabled:     slices.Contains(serviceConfig.RateProviders, registration.Name),
+				Latest:      registration.Capabilities.Latest,
+				Historical:  registration.Capabilities.Historical,
+				MultiSymbol: registration.Capabilities.MultiSymbol,
+			})
+		}
+
+		writeResponse(w, response)
+	})
+
+	server := &http.Server{Addr: serviceConfig.WorkerAdminAddress, Handler: mux}
+	context.AfterFunc(ctx, func() { server.Close() })
+
+	log.Printf("Worker admin endpoints are served on %s", serviceConfig.WorkerAdminAddress)
+	// The worker keeps processing updates without the admin endpoints
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Println("Unable to serve worker admin endpoints:", err)
+	}
+}
+
+func writeResponse(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
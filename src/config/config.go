@@ -1,12 +1,16 @@
 package config
 
 import (
+	"exchange-rates-service/src/internal/model"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 )
 
 type Config struct {
@@ -15,6 +19,40 @@ type Config struct {
 	WorkerFetchSize          int
 	WorkerTickInterval       time.Duration
 	HttpClientTimeout        time.Duration
+	ConversionRoundingMode   model.RoundingMode
+	TriangulationPivot       string
+	InverseRatePrecision     int32
+	CurrencyRefreshInterval  time.Duration
+	WorkerMaxAttempts        int
+	WorkerRetryBaseDelay     time.Duration
+	WorkerRetryMaxDelay      time.Duration
+	WorkerId                 string
+	WorkerLeaseDuration      time.Duration
+	WorkerReaperInterval     time.Duration
+	WorkerMaxUpdateAge       time.Duration
+	WorkerConcurrency        int
+	RateProviders            []string
+	RateAggregation          string
+	ConsensusMaxDeviation    decimal.Decimal
+	ConsensusMinQuotes       int
+	BreakerFailureThreshold  int
+	BreakerCoolDown          time.Duration
+	WorkerAdminAddress       string
+	ApiAdminAddress          string
+	ProviderRequestsPerMin   map[string]int
+	ProviderMonthlyQuotas    map[string]int
+	ProviderQuotaReserve     int
+	CurrencyApiBaseUrl       string
+	ExchangeIoBaseUrl        string
+	EcbBaseUrl               string
+	EcbCrossRatePrecision    int32
+	FrankfurterBaseUrl       string
+	OpenExchangeRatesAppId   string
+	OpenExchangeRatesBaseUrl string
+	FixerApiKey              string
+	FixerBaseUrl             string
+	ApiRequestTimeout        time.Duration
+	ShutdownGracePeriod      time.Duration
 }
 
 func NewConfig() *Config {
@@ -39,11 +77,15 @@ func NewConfig() *Config {
 	}
 
 	exchangeRatesApiKey := os.Getenv("EXCHANGE_RATES_IO_API_KEY")
-	if exchangeRatesApiKey == "" {
+	rateProviders := parseList(os.Getenv("RATE_PROVIDERS"))
+	if len(rateProviders) != 0 {
+		log.Printf("Rate providers %v will be used", rateProviders)
+	} else if exchangeRatesApiKey == "" {
 		log.Println("EXCHANGE_RATES_IO_API_KEY is not set. currency-api will be used")
+		rateProviders = []string{"currency-api"}
 	} else {
-		log.Println("EXCHANGE_RATES_IO_API_KEY found. exchangeratesapi.io api will be used")
-
+		log.Println("EXCHANGE_RATES_IO_API_KEY found. exchangeratesapi.io api will be used, currency-api is the fallback")
+		rateProviders = []string{"exchangeratesapi.io", "currency-api"}
 	}
 
 	httpClientTimeout, err := strconv.Atoi(os.Getenv("HTTP_CLIENT_TIMEOUT_MS"))
@@ -51,13 +93,220 @@ func NewConfig() *Config {
 		log.Fatalf("Unable to parse HTTP_CLIENT_TIMEOUT_MS: %s", err)
 	}
 
+	conversionRoundingMode, err := model.ParseRoundingMode(os.Getenv("CONVERSION_ROUNDING_MODE"))
+	if err != nil {
+		log.Fatalf("Unable to parse CONVERSION_ROUNDING_MODE: %s", err)
+	}
+
+	triangulationPivot := os.Getenv("TRIANGULATION_PIVOT_CURRENCY")
+	if triangulationPivot == "" {
+		log.Println("TRIANGULATION_PIVOT_CURRENCY is not set. Cross rates will use the shortest path")
+	}
+
+	inverseRatePrecision, err := strconv.ParseInt(os.Getenv("INVERSE_RATE_PRECISION"), 10, 32)
+	if err != nil {
+		log.Fatalf("Unable to parse INVERSE_RATE_PRECISION: %s", err)
+	}
+
+	currencyRefreshInterval, err := strconv.Atoi(os.Getenv("CURRENCY_REFRESH_INTERVAL_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse CURRENCY_REFRESH_INTERVAL_MILLISECONDS: %s", err)
+	}
+
+	workerMaxAttempts, err := strconv.Atoi(os.Getenv("WORKER_MAX_ATTEMPTS"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_MAX_ATTEMPTS: %s", err)
+	}
+
+	workerRetryBaseDelay, err := strconv.Atoi(os.Getenv("WORKER_RETRY_BASE_DELAY_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_RETRY_BASE_DELAY_MILLISECONDS: %s", err)
+	}
+
+	workerRetryMaxDelay, err := strconv.Atoi(os.Getenv("WORKER_RETRY_MAX_DELAY_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_RETRY_MAX_DELAY_MILLISECONDS: %s", err)
+	}
+
+	workerId := os.Getenv("WORKER_ID")
+	if workerId == "" {
+		hostname, _ := os.Hostname()
+		workerId = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	workerLeaseDuration, err := strconv.Atoi(os.Getenv("WORKER_LEASE_DURATION_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_LEASE_DURATION_MILLISECONDS: %s", err)
+	}
+
+	workerReaperInterval, err := strconv.Atoi(os.Getenv("WORKER_REAPER_INTERVAL_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_REAPER_INTERVAL_MILLISECONDS: %s", err)
+	}
+
+	workerMaxUpdateAge, err := strconv.Atoi(os.Getenv("WORKER_MAX_UPDATE_AGE_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_MAX_UPDATE_AGE_MILLISECONDS: %s", err)
+	}
+
+	workerConcurrency, err := strconv.Atoi(os.Getenv("WORKER_CONCURRENCY"))
+	if err != nil {
+		log.Fatalf("Unable to parse WORKER_CONCURRENCY: %s", err)
+	}
+
+	rateAggregation := os.Getenv("RATE_AGGREGATION")
+	if rateAggregation != "fallback" && rateAggregation != "consensus" {
+		log.Fatalf("Unable to parse RATE_AGGREGATION: expected fallback or consensus, got %q", rateAggregation)
+	}
+
+	consensusMaxDeviation, err := decimal.NewFromString(os.Getenv("CONSENSUS_MAX_DEVIATION_PERCENT"))
+	if err != nil {
+		log.Fatalf("Unable to parse CONSENSUS_MAX_DEVIATION_PERCENT: %s", err)
+	}
+
+	consensusMinQuotes, err := strconv.Atoi(os.Getenv("CONSENSUS_MIN_QUOTES"))
+	if err != nil {
+		log.Fatalf("Unable to parse CONSENSUS_MIN_QUOTES: %s", err)
+	}
+
+	breakerFailureThreshold, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_FAILURE_THRESHOLD"))
+	if err != nil {
+		log.Fatalf("Unable to parse CIRCUIT_BREAKER_FAILURE_THRESHOLD: %s", err)
+	}
+
+	breakerCoolDown, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_COOL_DOWN_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse CIRCUIT_BREAKER_COOL_DOWN_MILLISECONDS: %s", err)
+	}
+
+	workerAdminAddress := os.Getenv("WORKER_ADMIN_ADDRESS")
+	if workerAdminAddress == "" {
+		log.Println("WORKER_ADMIN_ADDRESS is not set. Worker admin endpoints are disabled")
+	}
+
+	apiAdminAddress := os.Getenv("API_ADMIN_ADDRESS")
+	if apiAdminAddress == "" {
+		log.Println("API_ADMIN_ADDRESS is not set. Currency admin endpoints are disabled")
+	}
+
+	providerRequestsPerMin, err := parseIntMap(os.Getenv("PROVIDER_REQUESTS_PER_MINUTE"))
+	if err != nil {
+		log.Fatalf("Unable to parse PROVIDER_REQUESTS_PER_MINUTE: %s", err)
+	}
+
+	providerMonthlyQuotas, err := parseIntMap(os.Getenv("PROVIDER_MONTHLY_QUOTAS"))
+	if err != nil {
+		log.Fatalf("Unable to parse PROVIDER_MONTHLY_QUOTAS: %s", err)
+	}
+
+	providerQuotaReserve, err := strconv.Atoi(os.Getenv("PROVIDER_QUOTA_RESERVE_PERCENT"))
+	if err != nil {
+		log.Fatalf("Unable to parse PROVIDER_QUOTA_RESERVE_PERCENT: %s", err)
+	}
+
+	currencyApiBaseUrl := getenvOrDefault("CURRENCY_API_BASE_URL",
+		"https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies")
+	exchangeIoBaseUrl := getenvOrDefault("EXCHANGE_RATES_IO_BASE_URL", "https://api.exchangeratesapi.io")
+	ecbBaseUrl := getenvOrDefault("ECB_BASE_URL", "https://www.ecb.europa.eu/stats/eurofxref")
+	ecbCrossRatePrecision, err := strconv.ParseInt(os.Getenv("ECB_CROSS_RATE_PRECISION"), 10, 32)
+	if err != nil {
+		log.Fatalf("Unable to parse ECB_CROSS_RATE_PRECISION: %s", err)
+	}
+
+	frankfurterBaseUrl := getenvOrDefault("FRANKFURTER_BASE_URL", "https://api.frankfurter.dev/v1")
+	openExchangeRatesBaseUrl := getenvOrDefault("OPEN_EXCHANGE_RATES_BASE_URL", "https://openexchangerates.org/api")
+	fixerBaseUrl := getenvOrDefault("FIXER_BASE_URL", "https://data.fixer.io/api")
+
+	apiRequestTimeout, err := strconv.Atoi(os.Getenv("API_REQUEST_TIMEOUT_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse API_REQUEST_TIMEOUT_MILLISECONDS: %s", err)
+	}
+
+	shutdownGracePeriod, err := strconv.Atoi(os.Getenv("SHUTDOWN_GRACE_PERIOD_MILLISECONDS"))
+	if err != nil {
+		log.Fatalf("Unable to parse SHUTDOWN_GRACE_PERIOD_MILLISECONDS: %s", err)
+	}
+
 	config := Config{
 		PostgresConnectionString: postgresConnectionString,
 		WorkerFetchSize:          workerFetchSize,
 		WorkerTickInterval:       time.Duration(WorkerTickInterval) * time.Millisecond,
 		ExchangeIoApiKey:         exchangeRatesApiKey,
 		HttpClientTimeout:        time.Duration(httpClientTimeout) * time.Millisecond,
+		ConversionRoundingMode:   conversionRoundingMode,
+		TriangulationPivot:       triangulationPivot,
+		InverseRatePrecision:     int32(inverseRatePrecision),
+		CurrencyRefreshInterval:  time.Duration(currencyRefreshInterval) * time.Millisecond,
+		WorkerMaxAttempts:        workerMaxAttempts,
+		WorkerRetryBaseDelay:     time.Duration(workerRetryBaseDelay) * time.Millisecond,
+		WorkerRetryMaxDelay:      time.Duration(workerRetryMaxDelay) * time.Millisecond,
+		WorkerId:                 workerId,
+		WorkerLeaseDuration:      time.Duration(workerLeaseDuration) * time.Millisecond,
+		WorkerReaperInterval:     time.Duration(workerReaperInterval) * time.Millisecond,
+		WorkerMaxUpdateAge:       time.Duration(workerMaxUpdateAge) * time.Millisecond,
+		WorkerConcurrency:        workerConcurrency,
+		RateProviders:            rateProviders,
+		RateAggregation:          rateAggregation,
+		ConsensusMaxDeviation:    consensusMaxDeviation.Div(decimal.NewFromInt(100)),
+		ConsensusMinQuotes:       consensusMinQuotes,
+		BreakerFailureThreshold:  breakerFailureThreshold,
+		BreakerCoolDown:          time.Duration(breakerCoolDown) * time.Millisecond,
+		WorkerAdminAddress:       workerAdminAddress,
+		ApiAdminAddress:          apiAdminAddress,
+		ProviderRequestsPerMin:   providerRequestsPerMin,
+		ProviderMonthlyQuotas:    providerMonthlyQuotas,
+		ProviderQuotaReserve:     providerQuotaReserve,
+		CurrencyApiBaseUrl:       currencyApiBaseUrl,
+		ExchangeIoBaseUrl:        exchangeIoBaseUrl,
+		EcbBaseUrl:               ecbBaseUrl,
+		EcbCrossRatePrecision:    int32(ecbCrossRatePrecision),
+		FrankfurterBaseUrl:       frankfurterBaseUrl,
+		OpenExchangeRatesAppId:   os.Getenv("OPEN_EXCHANGE_RATES_APP_ID"),
+		OpenExchangeRatesBaseUrl: openExchangeRatesBaseUrl,
+		FixerApiKey:              os.Getenv("FIXER_API_KEY"),
+		FixerBaseUrl:             fixerBaseUrl,
+		ApiRequestTimeout:        time.Duration(apiRequestTimeout) * time.Millisecond,
+		ShutdownGracePeriod:      time.Duration(shutdownGracePeriod) * time.Millisecond,
 	}
 
 	return &config
 }
+
+// getenvOrDefault returns the variable or the default value, which is logged when used
+func getenvOrDefault(name string, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	log.Printf("%s is not set. %s will be used", name, defaultValue)
+	return defaultValue
+}
+
+// parseList splits the comma separated value, empty items are skipped
+func parseList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseIntMap parses the comma separated name:value items
+func parseIntMap(value string) (map[string]int, error) {
+	items := make(map[string]int)
+	for _, item := range parseList(value) {
+		name, number, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected name:value, got %q", item)
+		}
+
+		parsed, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil {
+			return nil, err
+		}
+		items[strings.TrimSpace(name)] = parsed
+	}
+	return items, nil
+}
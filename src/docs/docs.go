@@ -15,9 +15,297 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/admin/v1/currencies/disable": {
+            "post": {
+                "description": "Disable currency. Exchange rate methods will return BadRequest for it. Stored rates are kept.\nServed only on the admin address API_ADMIN_ADDRESS, not on the public port",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-api"
+                ],
+                "summary": "Disable currency",
+                "parameters": [
+                    {
+                        "description": "Currency",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SetCurrencyActiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CurrencyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "BadRequest",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "NotFound",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/v1/currencies/enable": {
+            "post": {
+                "description": "Enable currency, so it can be used in exchange rate methods.\nServed only on the admin address API_ADMIN_ADDRESS, not on the public port",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-api"
+                ],
+                "summary": "Enable currency",
+                "parameters": [
+                    {
+                        "description": "Currency",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SetCurrencyActiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CurrencyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "BadRequest",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "NotFound",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rates/v1/convert": {
+            "get": {
+                "description": "Convert amount using the last exchange rate. The result is rounded to the minor units of the to currency. Returns the rate and its updateTime used for the conversion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rate-api"
+                ],
+                "summary": "Convert amount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From currency",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To currency",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Amount in from currency",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rounding mode: half-even, half-up or truncate. Service default is used if not set",
+                        "name": "rounding",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ConvertResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "BadRequest",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "NotFound",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rates/v1/currencies": {
+            "get": {
+                "description": "Get currencies which can be used in exchange rate methods. For each currency returns the pairs from it that have a stored rate with their last updateTime",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rate-api"
+                ],
+                "summary": "Get supported currencies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetCurrenciesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rates/v1/history": {
+            "get": {
+                "description": "Get rates stored for the currency pair ordered by updateTime. Use nextCursor from the response to get the next page, it is null on the last page",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rate-api"
+                ],
+                "summary": "Get exchange rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From currency",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To currency",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range in RFC3339 format, inclusive",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range in RFC3339 format, exclusive",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 100 by default, 1000 max",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetRateHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "BadRequest",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rates/v1/rates/last:batch": {
+            "post": {
+                "description": "Get stored rates of the pairs in one request. Rates are returned in the requested order, found is false when the pair has no stored rate. Rates are not derived from other pairs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rate-api"
+                ],
+                "summary": "Get last exchange rates in batch",
+                "parameters": [
+                    {
+                        "description": "Pairs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GetLastRateBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetLastRateBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "BadRequest",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/api/rates/v1/update": {
             "get": {
-                "description": "Get the exchange rate update by updateId. You can retrieve updateId in StartUpdateRate method. Returns rate and updateTime. Both will be null if the update was not performed",
+                "description": "Get the exchange rate update by updateId. You can retrieve updateId in StartUpdateRate method. Returns status (updating, done or error), rate and updateTime. Rate and updateTime will be null if the update was not performed, errorMessage contains the reason of the failed update. Provider is the rate provider which served the rate",
                 "consumes": [
                     "application/json"
                 ],
@@ -41,7 +329,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.GetRateResponse"
+                            "$ref": "#/definitions/model.GetRateUpdateResponse"
                         }
                     },
                     "400": {
@@ -61,7 +349,7 @@ const docTemplate = `{
         },
         "/api/rates/v1/update/last": {
             "get": {
-                "description": "Get exchange rate update started by StartUpdateRate method. Returns rate and updateTime",
+                "description": "Get exchange rate update started by StartUpdateRate method. Returns rate and updateTime. If the pair was never updated, the rate is derived from the opposite direction (derived is inverse) or built from the other stored rates (derived is cross). Legs contain the rates used, updateTime is the oldest leg time. Provider is the rate provider which served the stored rate",
                 "consumes": [
                     "application/json"
                 ],
@@ -86,6 +374,12 @@ const docTemplate = `{
                         "name": "to",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow inverse and cross rates when the pair is not stored, true by default",
+                        "name": "derived",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -143,20 +437,336 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/api/rates/v1/update/start:batch": {
+            "post": {
+                "description": "Start exchange rate updates for the list of pairs and/or for the base currency to each of the targets. Returns updateId or error for each pair, the key is from/to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rate-api"
+                ],
+                "summary": "Start exchange rate updates in batch",
+                "parameters": [
+                    {
+                        "description": "Batch update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StartUpdateRateBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.StartUpdateRateBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "BadRequest",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "model.ConvertResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "derived": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RateLegResponse"
+                    }
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "roundingMode": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "updateTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CurrencyPairResponse": {
+            "type": "object",
+            "properties": {
+                "to": {
+                    "type": "string"
+                },
+                "updateTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CurrencyRatesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "minorUnits": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "numericCode": {
+                    "type": "string"
+                },
+                "pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CurrencyPairResponse"
+                    }
+                }
+            }
+        },
+        "model.CurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "minorUnits": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "numericCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetCurrenciesResponse": {
+            "type": "object",
+            "properties": {
+                "currencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CurrencyRatesResponse"
+                    }
+                }
+            }
+        },
+        "model.GetLastRateBatchRequest": {
+            "type": "object",
+            "properties": {
+                "pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.StartUpdateRateRequest"
+                    }
+                }
+            }
+        },
+        "model.GetLastRateBatchResponse": {
+            "type": "object",
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LastRateBatchItem"
+                    }
+                }
+            }
+        },
+        "model.GetRateHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GetRateResponse"
+                    }
+                }
+            }
+        },
         "model.GetRateResponse": {
             "type": "object",
             "properties": {
+                "derived": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RateLegResponse"
+                    }
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "updateTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetRateUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
                 "rate": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "string"
+                },
                 "updateTime": {
                     "type": "string"
                 }
             }
         },
+        "model.LastRateBatchItem": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "found": {
+                    "type": "boolean"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "updateTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RateLegResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "inverted": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "updateTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SetCurrencyActiveRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.StartUpdateRateBatchItem": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "updateId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.StartUpdateRateBatchRequest": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.StartUpdateRateRequest"
+                    }
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.StartUpdateRateBatchResponse": {
+            "type": "object",
+            "properties": {
+                "updates": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.StartUpdateRateBatchItem"
+                    }
+                }
+            }
+        },
         "model.StartUpdateRateRequest": {
             "type": "object",
             "properties": {
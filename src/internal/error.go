@@ -1,5 +1,10 @@
 package internal
 
+import "errors"
+
+// ErrLeaseLost is returned when the worker writes the update whose lease expired or was taken by another worker
+var ErrLeaseLost = errors.New("update lease lost")
+
 type ErrorType int
 
 const (
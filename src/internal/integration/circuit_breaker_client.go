@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerClient stops calling the provider after the failure threshold of consecutive errors.
+// After the cool-down one probe call is let through: success closes the breaker, failure opens it again.
+// Missing or unsupported symbols, the exhausted quota and calls cancelled by the caller are not failures
+type CircuitBreakerClient struct {
+	provider         string
+	client           ExchangeRateApiClient
+	failureThreshold int
+	coolDown         time.Duration
+	now              func() time.Time
+
+	mutex    sync.Mutex
+	state    model.CircuitBreakerState
+	failures int
+	openedAt time.Time
+}
+
+func NewCircuitBreakerClient(config *config.Config, provider string, client ExchangeRateApiClient) *CircuitBreakerClient {
+	return &CircuitBreakerClient{
+		provider:         provider,
+		client:           client,
+		failureThreshold: max(config.BreakerFailureThreshold, 1),
+		coolDown:         config.BreakerCoolDown,
+		now:              time.Now,
+		state:            model.CircuitClosed,
+	}
+}
+
+func (c *CircuitBreakerClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+
+	rates, err := c.client.GetRates(ctx, base, targets)
+	c.release(ctx, err)
+	return rates, err
+}
+
+func (c *CircuitBreakerClient) Status() model.CircuitBreakerStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	status := model.CircuitBreakerStatus{
+		Provider: c.provider,
+		State:    c.state,
+		Failures: c.failures,
+	}
+
+	if c.state != model.CircuitClosed {
+		openedAt := c.openedAt
+		status.OpenedAt = &openedAt
+	}
+
+	return status
+}
+
+// acquire lets the call through while the breaker is closed. Only one probe call is let through when half-open
+func (c *CircuitBreakerClient) acquire() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch c.state {
+	case model.CircuitOpen:
+		if c.now().Sub(c.openedAt) < c.coolDown {
+			return fmt.Errorf("%s: %w", c.provider, ErrCircuitOpen)
+		}
+		c.state = model.CircuitHalfOpen
+		return nil
+	case model.CircuitHalfOpen:
+		return fmt.Errorf("%s: %w", c.provider, ErrCircuitOpen)
+	default:
+		return nil
+	}
+}
+
+func (c *CircuitBreakerClient) release(ctx context.Context, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if errors.Is(err, ErrQuotaExhausted) || (err != nil && ctx.Err() != nil) {
+		// The provider was not called or the caller gave up, the next call probes again
+		if c.state == model.CircuitHalfOpen {
+			c.state = model.CircuitOpen
+		}
+		return
+	}
+
+	// The provider answered, it just has no rate of the currency
+	if err == nil || errors.Is(err, ErrSymbolUnsupported) {
+		c.state = model.CircuitClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == model.CircuitHalfOpen || c.failures >= c.failureThreshold {
+		c.state = model.CircuitOpen
+		c.openedAt = c.now()
+	}
+}
+
+// CircuitBreakers are the breakers of all configured providers
+type CircuitBreakers []*CircuitBreakerClient
+
+func (breakers CircuitBreakers) Statuses() []model.CircuitBreakerStatus {
+	statuses := make([]model.CircuitBreakerStatus, 0, len(breakers))
+	for _, breaker := range breakers {
+		statuses = append(statuses, breaker.Status())
+	}
+	return statuses
+}
+
+// joinProviderErrors skips errors of providers which were not called because of the open breaker or the exhausted quota,
+// so the result wraps ErrCircuitOpen or ErrQuotaExhausted only when every provider was skipped
+func joinProviderErrors(errs []error) error {
+	failures := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrQuotaExhausted) {
+			failures = append(failures, err)
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+
+	return errors.Join(errs...)
+}
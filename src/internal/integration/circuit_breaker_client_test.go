@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerClient_ShouldOpenAfterFailureThreshold(t *testing.T) {
+	provider := new(mockApiClient)
+	breaker, _ := createCircuitBreaker(provider)
+
+	provider.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), errors.New("unavailable")).Twice()
+
+	_, err := breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	_, err = breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+
+	_, err = breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, model.CircuitOpen, breaker.Status().State)
+	assert.NotNil(t, breaker.Status().OpenedAt)
+	provider.AssertNumberOfCalls(t, "GetRates", 2)
+}
+
+func TestCircuitBreakerClient_ShouldCloseWhenProbeSucceeds(t *testing.T) {
+	provider := new(mockApiClient)
+	breaker, now := createCircuitBreaker(provider)
+
+	provider.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), errors.New("unavailable")).Twice()
+	breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+	breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	*now = now.Add(time.Minute)
+	provider.On("GetRates", "USD", []string{"EUR"}).Return(quotes("provider", "EUR", "0.85"), nil).Once()
+
+	rates, err := breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, rates, "EUR")
+	assert.Equal(t, model.CircuitBreakerStatus{Provider: "provider", State: model.CircuitClosed}, breaker.Status())
+}
+
+func TestCircuitBreakerClient_ShouldReopenWhenProbeFails(t *testing.T) {
+	provider := new(mockApiClient)
+	breaker, now := createCircuitBreaker(provider)
+
+	provider.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), errors.New("unavailable"))
+	breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+	breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	*now = now.Add(time.Minute)
+	_, err := breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+
+	_, err = breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, *now, *breaker.Status().OpenedAt)
+	provider.AssertNumberOfCalls(t, "GetRates", 3)
+}
+
+func TestFallbackClient_ShouldReturnCircuitOpenOnlyWhenAllBreakersOpen(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	firstBreaker, _ := createCircuitBreaker(first)
+	client := NewFallbackClient(namedClients(firstBreaker, second)...)
+
+	first.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), errors.New("unavailable")).Twice()
+	firstBreaker.GetRates(context.Background(), "USD", []string{"EUR"})
+	firstBreaker.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	secondError := errors.New("second error")
+	second.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), secondError).Once()
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+	assert.ErrorIs(t, err, secondError)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+
+	_, err = NewFallbackClient(namedClients(firstBreaker)...).GetRates(context.Background(), "USD", []string{"EUR"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	first.AssertNumberOfCalls(t, "GetRates", 2)
+}
+
+// createCircuitBreaker opens after 2 failures for 30 seconds, the returned time is the breaker clock
+func createCircuitBreaker(provider ExchangeRateApiClient) (*CircuitBreakerClient, *time.Time) {
+	config := &config.Config{
+		BreakerFailureThreshold: 2,
+		BreakerCoolDown:         30 * time.Second,
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	breaker := NewCircuitBreakerClient(config, "provider", provider)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
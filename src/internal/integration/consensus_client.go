@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+const ConsensusProvider = "consensus"
+
+// ConsensusClient asks all providers concurrently and returns the median of their quotes.
+// Quotes deviating from the median by more than the max deviation are rejected
+type ConsensusClient struct {
+	providers    []NamedClient
+	maxDeviation decimal.Decimal
+	minQuotes    int
+}
+
+func NewConsensusClient(config *config.Config, providers ...NamedClient) ExchangeRateApiClient {
+	return &ConsensusClient{
+		providers:    providers,
+		maxDeviation: config.ConsensusMaxDeviation,
+		minQuotes:    config.ConsensusMinQuotes,
+	}
+}
+
+// GetRates returns an error only if all providers failed.
+// The error wraps ErrCircuitOpen or ErrQuotaExhausted when all providers were skipped by their breakers or quotas.
+// Targets with less accepted quotes than the min quotes are not in the result, their *ConsensusError is returned
+// in *PartialRatesError together with the rates of the other targets. Targets without any quote get the joined
+// errors of the providers instead, ErrSymbolUnsupported for the providers which had no rate
+func (c *ConsensusClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	results := make([]map[string]model.RateQuote, len(c.providers))
+	errs := make([]error, len(c.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range c.providers {
+		wg.Go(func() {
+			results[i], errs[i] = provider.Client.GetRates(ctx, base, targets)
+		})
+	}
+	wg.Wait()
+
+	if !slices.ContainsFunc(errs, func(err error) bool { return err == nil }) {
+		return nil, joinProviderErrors(errs)
+	}
+
+	rates := make(map[string]model.RateQuote, len(targets))
+	targetErrors := make(map[string]error)
+	for _, target := range targets {
+		quotes := make([]model.ProviderQuote, 0, len(results))
+		for _, result := range results {
+			if quote, ok := result[target]; ok {
+				quotes = append(quotes, model.ProviderQuote{Provider: quote.Provider, Rate: quote.Rate})
+			}
+		}
+
+		// Targets without any quote are unsupported only when every provider answered without the rate
+		if len(quotes) == 0 {
+			providerErrors := make([]error, 0, len(c.providers))
+			for i, provider := range c.providers {
+				if errs[i] != nil {
+					providerErrors = append(providerErrors, errs[i])
+					continue
+				}
+				providerErrors = append(providerErrors, symbolNotFound(provider.Name, base, target))
+			}
+			targetErrors[target] = errors.Join(providerErrors...)
+			continue
+		}
+
+		rate, err := c.consensus(base, target, quotes)
+		if err != nil {
+			targetErrors[target] = err
+			continue
+		}
+		rates[target] = rate
+	}
+
+	if len(targetErrors) != 0 {
+		return rates, &PartialRatesError{Errors: targetErrors}
+	}
+
+	return rates, nil
+}
+
+func (c *ConsensusClient) consensus(base string, target string, quotes []model.ProviderQuote) (model.RateQuote, error) {
+
+	allRates := make([]decimal.Decimal, 0, len(quotes))
+	for _, quote := range quotes {
+		allRates = append(allRates, quote.Rate)
+	}
+	median := medianRate(allRates)
+
+	accepted := make([]decimal.Decimal, 0, len(quotes))
+	for i := range quotes {
+		if median.IsZero() || quotes[i].Rate.Sub(median).Abs().Div(median).GreaterThan(c.maxDeviation) {
+			quotes[i].Rejected = true
+			continue
+		}
+		accepted = append(accepted, quotes[i].Rate)
+	}
+
+	if len(accepted) == 0 || len(accepted) < c.minQuotes {
+		return model.RateQuote{}, &ConsensusError{Base: base, Target: target, Quotes: quotes, MinQuotes: c.minQuotes}
+	}
+
+	spread := slices.MaxFunc(accepted, decimal.Decimal.Cmp).Sub(slices.MinFunc(accepted, decimal.Decimal.Cmp))
+	return model.RateQuote{
+		Rate:     medianRate(accepted),
+		Provider: ConsensusProvider,
+		Sources:  quotes,
+		Spread:   &spread,
+	}, nil
+}
+
+// ConsensusError is the target with less accepted quotes than the min quotes.
+// Quotes are all collected quotes, the deviating ones are rejected
+type ConsensusError struct {
+	Base      string
+	Target    string
+	Quotes    []model.ProviderQuote
+	MinQuotes int
+}
+
+// Error lists the quotes, so they are kept in the error message of the failed update
+func (e *ConsensusError) Error() string {
+	accepted := 0
+	quotes := make([]string, 0, len(e.Quotes))
+	for _, quote := range e.Quotes {
+		value := quote.Provider + " " + quote.Rate.String()
+		if quote.Rejected {
+			value += " rejected"
+		} else {
+			accepted++
+		}
+		quotes = append(quotes, value)
+	}
+
+	return fmt.Sprintf("consensus rate %s/%s not reached, %d of %d required quotes accepted: %s",
+		e.Base, e.Target, accepted, e.MinQuotes, strings.Join(quotes, ", "))
+}
+
+// PartialRatesError is returned together with the rates of the other targets, Errors are the errors of missing targets
+type PartialRatesError struct {
+	Errors map[string]error
+}
+
+func (e *PartialRatesError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, target := range slices.Sorted(maps.Keys(e.Errors)) {
+		messages = append(messages, e.Errors[target].Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// medianRate returns the middle rate, the mean of two middle rates for even count
+func medianRate(rates []decimal.Decimal) decimal.Decimal {
+	sorted := slices.SortedFunc(slices.Values(rates), decimal.Decimal.Cmp)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2))
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusClient_ShouldRejectOutlierAndReturnMedian(t *testing.T) {
+	first, second, third := new(mockApiClient), new(mockApiClient), new(mockApiClient)
+	client := createConsensusClient(2, first, second, third)
+
+	first.On("GetRates", "USD", []string{"EUR"}).Return(quotes("first", "EUR", "0.850"), nil)
+	second.On("GetRates", "USD", []string{"EUR"}).Return(quotes("second", "EUR", "0.852"), nil)
+	third.On("GetRates", "USD", []string{"EUR"}).Return(quotes("third", "EUR", "0.95"), nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.NoError(t, err)
+	rate := rates["EUR"]
+	assert.Equal(t, "0.851", rate.Rate.String())
+	assert.Equal(t, ConsensusProvider, rate.Provider)
+	assert.Equal(t, "0.002", rate.Spread.String())
+	assert.Len(t, rate.Sources, 3)
+	assert.False(t, rate.Sources[0].Rejected)
+	assert.False(t, rate.Sources[1].Rejected)
+	assert.True(t, rate.Sources[2].Rejected)
+	assert.Equal(t, "third", rate.Sources[2].Provider)
+}
+
+func TestConsensusClient_ShouldReturnQuotesOfTargetWithoutEnoughQuotes(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := createConsensusClient(2, first, second)
+
+	first.On("GetRates", "USD", []string{"EUR", "MXN"}).Return(map[string]model.RateQuote{
+		"EUR": {Rate: decimal.RequireFromString("0.85"), Provider: "first"},
+		"MXN": {Rate: decimal.RequireFromString("17.1"), Provider: "first"},
+	}, nil)
+	second.On("GetRates", "USD", []string{"EUR", "MXN"}).Return(map[string]model.RateQuote{
+		"EUR": {Rate: decimal.RequireFromString("0.95"), Provider: "second"},
+		"MXN": {Rate: decimal.RequireFromString("17.1"), Provider: "second"},
+	}, nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "MXN"})
+
+	var partial *PartialRatesError
+	assert.ErrorAs(t, err, &partial)
+	assert.Contains(t, rates, "MXN")
+	assert.NotContains(t, rates, "EUR")
+
+	var consensusError *ConsensusError
+	assert.ErrorAs(t, partial.Errors["EUR"], &consensusError)
+	assert.Len(t, consensusError.Quotes, 2)
+	assert.Equal(t,
+		"consensus rate USD/EUR not reached, 0 of 2 required quotes accepted: first 0.85 rejected, second 0.95 rejected",
+		consensusError.Error())
+}
+
+func TestConsensusClient_ShouldReturnProviderErrorsOfTargetWithoutQuotes(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := createConsensusClient(2, first, second)
+
+	unavailable := errors.New("unavailable")
+	first.On("GetRates", "USD", []string{"XXX"}).Return(map[string]model.RateQuote{}, nil)
+	second.On("GetRates", "USD", []string{"XXX"}).Return(map[string]model.RateQuote(nil), unavailable)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"XXX"})
+
+	var partial *PartialRatesError
+	assert.ErrorAs(t, err, &partial)
+	assert.Empty(t, rates)
+	assert.ErrorIs(t, partial.Errors["XXX"], ErrSymbolUnsupported)
+	assert.ErrorIs(t, partial.Errors["XXX"], unavailable)
+	assert.False(t, IsPermanent(partial.Errors["XXX"]))
+}
+
+func TestConsensusClient_ShouldReturnErrorWhenAllProvidersFail(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := createConsensusClient(1, first, second)
+
+	firstError, secondError := errors.New("first error"), errors.New("second error")
+	first.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), firstError)
+	second.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), secondError)
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, firstError)
+	assert.ErrorIs(t, err, secondError)
+}
+
+func TestMedianRate_ShouldAverageMiddleRatesForEvenCount(t *testing.T) {
+	rates := []decimal.Decimal{
+		decimal.RequireFromString("4"),
+		decimal.RequireFromString("1"),
+		decimal.RequireFromString("3"),
+		decimal.RequireFromString("2"),
+	}
+
+	assert.Equal(t, "2.5", medianRate(rates).String())
+	assert.Equal(t, "2", medianRate(rates[1:]).String())
+}
+
+func createConsensusClient(minQuotes int, providers ...ExchangeRateApiClient) ExchangeRateApiClient {
+	config := &config.Config{
+		ConsensusMaxDeviation: decimal.RequireFromString("0.01"),
+		ConsensusMinQuotes:    minQuotes,
+	}
+	return NewConsensusClient(config, namedClients(providers...)...)
+}
+
+func quotes(provider string, target string, rate string) map[string]model.RateQuote {
+	return map[string]model.RateQuote{
+		target: {Rate: decimal.RequireFromString(rate), Provider: provider},
+	}
+}
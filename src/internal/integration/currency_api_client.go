@@ -1,8 +1,10 @@
 package integration
 
 import (
+	"context"
 	"encoding/json"
 	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,7 +13,8 @@ import (
 )
 
 type CurrencyApiClient struct {
-	client *http.Client
+	client  *http.Client
+	baseUrl string
 }
 
 func NewCurrencyApiClient(config *config.Config) ExchangeRateApiClient {
@@ -19,49 +22,60 @@ func NewCurrencyApiClient(config *config.Config) ExchangeRateApiClient {
 		client: &http.Client{
 			Timeout: config.HttpClientTimeout,
 		},
+		baseUrl: config.CurrencyApiBaseUrl,
 	}
 }
 
-const currencyApiBaseUrl = "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies"
+const CurrencyApiProvider = "currency-api"
 
-func (c *CurrencyApiClient) GetRate(from string, to string) (decimal.Decimal, error) {
-	fromLower := strings.ToLower(from)
-	toLower := strings.ToLower(to)
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name: CurrencyApiProvider,
+		New: func(config *config.Config) (ExchangeRateApiClient, error) {
+			return NewCurrencyApiClient(config), nil
+		},
+		Capabilities: ProviderCapabilities{Latest: true, MultiSymbol: true},
+	})
+}
 
-	fullUrl := fmt.Sprintf("%s/%s.json", currencyApiBaseUrl, fromLower)
-	resp, err := c.client.Get(fullUrl)
-	if err != nil {
-		return decimal.Decimal{}, err
-	}
-	defer resp.Body.Close()
+// GetRates uses the response of the base currency, which contains rates to all currencies known by the api
+func (c *CurrencyApiClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	baseLower := strings.ToLower(base)
 
+	fullUrl := fmt.Sprintf("%s/%s.json", c.baseUrl, baseLower)
 	responseMap := make(map[string]any)
-	dec := json.NewDecoder(resp.Body)
-	dec.UseNumber()
-	err = dec.Decode(&responseMap)
-	if err != nil {
-		return decimal.Decimal{}, err
+	if err := getJson(ctx, c.client, CurrencyApiProvider, fullUrl, &responseMap); err != nil {
+		return nil, err
 	}
 
-	fromRates, ok := responseMap[fromLower]
+	baseRates, ok := responseMap[baseLower]
 	if !ok {
-		return decimal.Decimal{}, fmt.Errorf("expected %s in response, got %v", fromLower, responseMap)
+		return nil, fmt.Errorf("expected %s in response, got %v", baseLower, responseMap)
 	}
 
-	fromRatesMap, ok := fromRates.(map[string]any)
+	baseRatesMap, ok := baseRates.(map[string]any)
 	if !ok {
-		return decimal.Decimal{}, fmt.Errorf("cannot deserialize to map: %v", fromRates)
+		return nil, fmt.Errorf("cannot deserialize to map: %v", baseRates)
 	}
 
-	rate, ok := fromRatesMap[toLower]
-	if !ok {
-		return decimal.Decimal{}, fmt.Errorf("rate %s not found in %v", toLower, fromRatesMap)
-	}
+	rates := make(map[string]model.RateQuote, len(targets))
+	for _, target := range targets {
+		rate, ok := baseRatesMap[strings.ToLower(target)]
+		if !ok {
+			continue
+		}
 
-	rateNumber, ok := rate.(json.Number)
-	if !ok {
-		return decimal.Decimal{}, fmt.Errorf("rate %v is not a number", rate)
+		rateNumber, ok := rate.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("rate %v is not a number", rate)
+		}
+
+		value, err := decimal.NewFromString(rateNumber.String())
+		if err != nil {
+			return nil, err
+		}
+		rates[target] = model.RateQuote{Rate: value, Provider: CurrencyApiProvider}
 	}
 
-	return decimal.NewFromString(rateNumber.String())
+	return rates, nil
 }
@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyApiClient_ShouldReturnRatesOfAllSymbols(t *testing.T) {
+	client := createCurrencyApiClient(t)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "MXN", "CHF"})
+
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "0.91827", rates["EUR"].Rate.String())
+	assert.Equal(t, "16.6781", rates["MXN"].Rate.String())
+	assert.Equal(t, CurrencyApiProvider, rates["EUR"].Provider)
+}
+
+func TestCurrencyApiClient_ShouldReturnUnsupportedSymbolOfUnknownBase(t *testing.T) {
+	client := createCurrencyApiClient(t)
+
+	_, err := client.GetRates(context.Background(), "XXX", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrSymbolUnsupported)
+}
+
+// createCurrencyApiClient serves the testdata fixture of USD base, other bases are not found like on the cdn
+func createCurrencyApiClient(t *testing.T) *CurrencyApiClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/usd.json" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/currency-api-usd.json")
+	}))
+	t.Cleanup(server.Close)
+
+	return NewCurrencyApiClient(&config.Config{CurrencyApiBaseUrl: server.URL, HttpClientTimeout: time.Second}).(*CurrencyApiClient)
+}
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"context"
+	"encoding/xml"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// EcbClient reads the European Central Bank reference rates. The rates are published for EUR base on working days,
+// rates of other bases are cross rates through EUR
+type EcbClient struct {
+	config  *config.Config
+	client  *http.Client
+	baseUrl string
+	now     func() time.Time
+}
+
+type ecbEnvelope struct {
+	Days []ecbDay `xml:"Cube>Cube"`
+}
+
+type ecbDay struct {
+	Time  string    `xml:"time,attr"`
+	Rates []ecbRate `xml:"Cube"`
+}
+
+type ecbRate struct {
+	Currency string          `xml:"currency,attr"`
+	Rate     decimal.Decimal `xml:"rate,attr"`
+}
+
+func NewEcbClient(config *config.Config) *EcbClient {
+	return &EcbClient{
+		config:  config,
+		client:  &http.Client{Timeout: config.HttpClientTimeout},
+		baseUrl: config.EcbBaseUrl,
+		now:     time.Now,
+	}
+}
+
+const (
+	EcbProvider = "ecb"
+	ecbBase     = "EUR"
+
+	ecbDailyFile      = "eurofxref-daily.xml"
+	ecb90DaysFile     = "eurofxref-hist-90d.xml"
+	ecbHistoricalFile = "eurofxref-hist.xml"
+
+	ecb90DaysPeriod = 90 * 24 * time.Hour
+)
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name: EcbProvider,
+		New: func(config *config.Config) (ExchangeRateApiClient, error) {
+			return NewEcbClient(config), nil
+		},
+		Capabilities: ProviderCapabilities{Latest: true, Historical: true, MultiSymbol: true},
+	})
+}
+
+// GetRates returns the latest reference rates
+func (c *EcbClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	days, err := c.getDays(ctx, ecbDailyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no rates in %s", ecbDailyFile)
+	}
+
+	return c.crossRates(days[0], base, targets), nil
+}
+
+// GetRatesOn returns the reference rates published on the date or the last working day before it.
+// Dates of the last 90 days are read from the 90 days file, older dates from the full history file.
+// The full history file is read also when the 90 days file starts after the date, e.g. after a holiday
+func (c *EcbClient) GetRatesOn(ctx context.Context, base string, targets []string, date time.Time) (map[string]model.RateQuote, error) {
+	day := date.UTC().Format(time.DateOnly)
+
+	if c.now().Sub(date) < ecb90DaysPeriod {
+		days, err := c.getDays(ctx, ecb90DaysFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if published, ok := publishedOn(days, day); ok {
+			return c.crossRates(published, base, targets), nil
+		}
+	}
+
+	days, err := c.getDays(ctx, ecbHistoricalFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if published, ok := publishedOn(days, day); ok {
+		return c.crossRates(published, base, targets), nil
+	}
+
+	return nil, fmt.Errorf("no rates published on or before %s", day)
+}
+
+// publishedOn returns the day published on the date or the last one before it, days are ordered from the latest
+func publishedOn(days []ecbDay, date string) (ecbDay, bool) {
+	for _, published := range days {
+		if published.Time <= date {
+			return published, true
+		}
+	}
+	return ecbDay{}, false
+}
+
+func (c *EcbClient) getDays(ctx context.Context, file string) ([]ecbDay, error) {
+	resp, err := getContext(ctx, c.client, fmt.Sprintf("%s/%s", c.baseUrl, file))
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, &ProviderError{Provider: EcbProvider, Message: err.Error(), Err: ErrUpstreamUnavailable}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// A missing file is not an unsupported currency
+		err = errorOfStatus(resp.StatusCode)
+		if resp.StatusCode == http.StatusNotFound {
+			err = ErrUpstreamUnavailable
+		}
+		return nil, &ProviderError{Provider: EcbProvider, StatusCode: resp.StatusCode, Message: file, Err: err}
+	}
+
+	envelope := ecbEnvelope{}
+	if err = xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	return envelope.Days, nil
+}
+
+// crossRates divides the EUR rate of the target by the EUR rate of the base, rounded to EcbCrossRatePrecision
+// decimal places. Rates of EUR base are returned as published.
+// Currencies not published that day are not in the result
+func (c *EcbClient) crossRates(day ecbDay, base string, targets []string) map[string]model.RateQuote {
+	eurRates := make(map[string]decimal.Decimal, len(day.Rates)+1)
+	eurRates[ecbBase] = decimal.NewFromInt(1)
+	for _, rate := range day.Rates {
+		eurRates[rate.Currency] = rate.Rate
+	}
+
+	rates := make(map[string]model.RateQuote, len(targets))
+	baseRate, ok := eurRates[base]
+	if !ok || baseRate.IsZero() {
+		return rates
+	}
+
+	for _, target := range targets {
+		targetRate, ok := eurRates[target]
+		if !ok {
+			continue
+		}
+
+		rate := targetRate
+		if base != ecbBase {
+			rate = targetRate.DivRound(baseRate, c.config.EcbCrossRatePrecision)
+		}
+		rates[target] = model.RateQuote{Rate: rate, Provider: EcbProvider}
+	}
+
+	return rates
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcbClient_ShouldReturnEurRates(t *testing.T) {
+	client := createEcbClient(t)
+
+	rates, err := client.GetRates(context.Background(), "EUR", []string{"USD", "MXN", "CHF"})
+
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "1.089", rates["USD"].Rate.String())
+	assert.Equal(t, "18.1625", rates["MXN"].Rate.String())
+	assert.Equal(t, EcbProvider, rates["USD"].Provider)
+}
+
+func TestEcbClient_ShouldComputeCrossRates(t *testing.T) {
+	client := createEcbClient(t)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"MXN", "EUR"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "16.678145", rates["MXN"].Rate.String())
+	assert.Equal(t, "0.918274", rates["EUR"].Rate.String())
+}
+
+func TestEcbClient_ShouldReturnNoRatesForUnknownBase(t *testing.T) {
+	client := createEcbClient(t)
+
+	rates, err := client.GetRates(context.Background(), "CHF", []string{"EUR"})
+
+	require.NoError(t, err)
+	assert.Empty(t, rates)
+}
+
+func TestEcbClient_ShouldReadRecentDateFrom90DaysFile(t *testing.T) {
+	client := createEcbClient(t)
+
+	// Weekend date returns the rates of the last working day
+	rates, err := client.GetRatesOn(context.Background(), "EUR", []string{"USD"}, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0932", rates["USD"].Rate.String())
+}
+
+func TestEcbClient_ShouldReadOldDateFromHistoricalFile(t *testing.T) {
+	client := createEcbClient(t)
+
+	rates, err := client.GetRatesOn(context.Background(), "USD", []string{"MXN"}, time.Date(2001, 1, 5, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "9.619806", rates["MXN"].Rate.String())
+}
+
+func TestEcbClient_ShouldReadHistoricalFileWhen90DaysFileStartsAfterDate(t *testing.T) {
+	client := createEcbClient(t)
+
+	// The date is within 90 days, but the 90 days file starts on 2024-03-08
+	rates, err := client.GetRatesOn(context.Background(), "EUR", []string{"USD"}, time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0895", rates["USD"].Rate.String())
+}
+
+func TestEcbClient_ShouldReturnErrorBeforeFirstPublication(t *testing.T) {
+	client := createEcbClient(t)
+
+	_, err := client.GetRatesOn(context.Background(), "EUR", []string{"USD"}, time.Date(1998, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	assert.Error(t, err)
+}
+
+// createEcbClient serves the testdata fixtures, the client clock is on the day of the daily file
+func createEcbClient(t *testing.T) *EcbClient {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(server.Close)
+
+	config := &config.Config{
+		EcbBaseUrl:            server.URL,
+		HttpClientTimeout:     time.Second,
+		EcbCrossRatePrecision: 6,
+	}
+
+	client := NewEcbClient(config)
+	client.now = func() time.Time { return time.Date(2024, 3, 15, 16, 0, 0, 0, time.UTC) }
+	return client
+}
@@ -1,17 +1,20 @@
 package integration
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
 
 type ExchangeRateApiClient interface {
-	GetRate(from string, to string) (decimal.Decimal, error)
+	// GetRates returns rates from base to the targets in one provider call. Targets the provider has no rate for
+	// are not in the result
+	GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error)
 }
 
 type ExchangeRateApiIoClient struct {
@@ -24,6 +27,7 @@ type ExchangeRateApiIoResponse struct {
 	Timestamp uint64                     `json:"timestamp"`
 	Base      string                     `json:"base"`
 	Rates     map[string]decimal.Decimal `json:"rates"`
+	Error     *ApiLayerError             `json:"error"`
 }
 
 func NewExchangeRateApiIoClient(config *config.Config) ExchangeRateApiClient {
@@ -35,32 +39,46 @@ func NewExchangeRateApiIoClient(config *config.Config) ExchangeRateApiClient {
 	}
 }
 
-const exchangeRatesApiIoBaseUrl = "https://api.exchangeratesapi.io"
+const ExchangeRatesApiIoProvider = "exchangeratesapi.io"
 
-func (c *ExchangeRateApiIoClient) GetRate(from string, to string) (decimal.Decimal, error) {
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name: ExchangeRatesApiIoProvider,
+		New: func(config *config.Config) (ExchangeRateApiClient, error) {
+			if config.ExchangeIoApiKey == "" {
+				return nil, fmt.Errorf("provider %s requires EXCHANGE_RATES_IO_API_KEY", ExchangeRatesApiIoProvider)
+			}
+			return NewExchangeRateApiIoClient(config), nil
+		},
+		Capabilities: ProviderCapabilities{Latest: true, MultiSymbol: true},
+	})
+}
+
+func (c *ExchangeRateApiIoClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
 	apiKey := c.config.ExchangeIoApiKey
-	fullUrl := fmt.Sprintf("%s/v1/latest?access_key=%s&base=%s&symbols=%s", exchangeRatesApiIoBaseUrl, apiKey, from, to)
+	fullUrl := fmt.Sprintf("%s/v1/latest?access_key=%s&base=%s&symbols=%s",
+		c.config.ExchangeIoBaseUrl, apiKey, base, strings.Join(targets, ","))
 
-	resp, err := c.client.Get(fullUrl)
-	if err != nil {
-		return decimal.Decimal{}, err
+	response := ExchangeRateApiIoResponse{}
+	err := getJson(ctx, c.client, ExchangeRatesApiIoProvider, fullUrl, &response)
+	if response.Error != nil {
+		return nil, response.Error.providerError(ExchangeRatesApiIoProvider)
 	}
-	defer resp.Body.Close()
 
-	response := ExchangeRateApiIoResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
-		return decimal.Decimal{}, err
+		return nil, err
 	}
 
 	if !response.Success {
-		return decimal.Decimal{}, errors.New("Error parsing response")
+		return nil, &ProviderError{Provider: ExchangeRatesApiIoProvider, Message: "request was not successful"}
 	}
 
-	rate, ok := response.Rates[to]
-	if !ok {
-		return decimal.Decimal{}, fmt.Errorf("response not returned for %s rate", to)
+	rates := make(map[string]model.RateQuote, len(targets))
+	for _, target := range targets {
+		if rate, ok := response.Rates[target]; ok {
+			rates[target] = model.RateQuote{Rate: rate, Provider: ExchangeRatesApiIoProvider}
+		}
 	}
 
-	return rate, nil
+	return rates, nil
 }
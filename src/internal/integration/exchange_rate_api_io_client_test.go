@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRateApiIoClient_ShouldReturnRatesOfAllSymbols(t *testing.T) {
+	client := createExchangeRateApiIoClient(t, "access-key")
+
+	rates, err := client.GetRates(context.Background(), "EUR", []string{"USD", "MXN", "CHF"})
+
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "1.089", rates["USD"].Rate.String())
+	assert.Equal(t, "18.1625", rates["MXN"].Rate.String())
+	assert.Equal(t, ExchangeRatesApiIoProvider, rates["USD"].Provider)
+}
+
+func TestExchangeRateApiIoClient_ShouldReturnErrorOfInvalidAccessKey(t *testing.T) {
+	client := createExchangeRateApiIoClient(t, "secret-key")
+
+	_, err := client.GetRates(context.Background(), "EUR", []string{"USD"})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.EqualError(t, err, "exchangeratesapi.io 101 invalid_access_key: You have not supplied a valid API Access Key.")
+	assert.NotContains(t, err.Error(), "secret-key")
+}
+
+func TestExchangeRateApiIoClient_ShouldReturnUnsupportedSymbolOfInvalidBase(t *testing.T) {
+	client := createExchangeRateApiIoClient(t, "access-key")
+
+	_, err := client.GetRates(context.Background(), "XXX", []string{"USD"})
+
+	assert.ErrorIs(t, err, ErrSymbolUnsupported)
+}
+
+// createExchangeRateApiIoClient serves the EUR fixture, other bases are invalid
+func createExchangeRateApiIoClient(t *testing.T, apiKey string) *ExchangeRateApiIoClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("access_key") != "access-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			body, _ := os.ReadFile("testdata/exchangeratesapi-invalid-access-key.json")
+			w.Write(body)
+			return
+		}
+
+		if r.URL.Path != "/v1/latest" || r.URL.Query().Get("base") != "EUR" {
+			w.WriteHeader(http.StatusBadRequest)
+			body, _ := os.ReadFile("testdata/exchangeratesapi-invalid-base.json")
+			w.Write(body)
+			return
+		}
+		http.ServeFile(w, r, "testdata/exchangeratesapi-latest.json")
+	}))
+	t.Cleanup(server.Close)
+
+	return NewExchangeRateApiIoClient(&config.Config{
+		ExchangeIoBaseUrl: server.URL,
+		ExchangeIoApiKey:  apiKey,
+		HttpClientTimeout: time.Second,
+	}).(*ExchangeRateApiIoClient)
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+)
+
+// FallbackClient asks the providers in order. Targets which the provider failed or has no rate for
+// are requested from the next provider
+type FallbackClient struct {
+	providers []NamedClient
+}
+
+// NamedClient is the client of one provider, the name is given to the errors of targets the provider has no rate for
+type NamedClient struct {
+	Name   string
+	Client ExchangeRateApiClient
+}
+
+func NewFallbackClient(providers ...NamedClient) ExchangeRateApiClient {
+	return &FallbackClient{providers: providers}
+}
+
+// ProviderStatus gives the breakers and the limiters of the configured providers
+type ProviderStatus struct {
+	Breakers CircuitBreakers
+	Limiters RateLimitedClients
+}
+
+// NewExchangeRateApiClient creates the client of the registered providers listed in config.RateProviders.
+// Every provider is limited by its request rate and monthly quota and wrapped in its circuit breaker.
+// Providers are asked in order, or all at once when config.RateAggregation is consensus
+func NewExchangeRateApiClient(config *config.Config, usage QuotaCounter) (ExchangeRateApiClient, ProviderStatus, error) {
+	providers := make([]NamedClient, 0, len(config.RateProviders))
+	status := ProviderStatus{
+		Breakers: make(CircuitBreakers, 0, len(config.RateProviders)),
+		Limiters: make(RateLimitedClients, 0, len(config.RateProviders)),
+	}
+	for _, name := range config.RateProviders {
+		provider, err := newProvider(config, name)
+		if err != nil {
+			return nil, ProviderStatus{}, err
+		}
+
+		limiter := NewRateLimitedClient(config, name, provider, usage)
+		breaker := NewCircuitBreakerClient(config, name, limiter)
+		status.Limiters = append(status.Limiters, limiter)
+		status.Breakers = append(status.Breakers, breaker)
+		providers = append(providers, NamedClient{Name: name, Client: breaker})
+	}
+
+	if len(providers) == 0 {
+		return nil, ProviderStatus{}, errors.New("no rate providers configured")
+	}
+
+	if config.RateAggregation == "consensus" {
+		if len(providers) < config.ConsensusMinQuotes {
+			return nil, ProviderStatus{}, fmt.Errorf("consensus requires %d rate providers, %d configured",
+				config.ConsensusMinQuotes, len(providers))
+		}
+		return NewConsensusClient(config, providers...), status, nil
+	}
+
+	return NewFallbackClient(providers...), status, nil
+}
+
+// GetRates returns an error only if all providers failed.
+// The error wraps ErrCircuitOpen or ErrQuotaExhausted when all providers were skipped by their breakers or quotas.
+// Targets missing in every provider are not in the result, *PartialRatesError joins the errors of the providers
+// asked for them: the provider error, or ErrSymbolUnsupported when the provider had no rate
+func (c *FallbackClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	rates := make(map[string]model.RateQuote, len(targets))
+	remaining := targets
+	errs := make([]error, 0)
+	targetErrors := make(map[string][]error)
+
+	for _, provider := range c.providers {
+		if len(remaining) == 0 {
+			break
+		}
+
+		providerRates, err := provider.Client.GetRates(ctx, base, remaining)
+		if err != nil {
+			errs = append(errs, err)
+			for _, target := range remaining {
+				targetErrors[target] = append(targetErrors[target], err)
+			}
+			continue
+		}
+
+		missing := make([]string, 0)
+		for _, target := range remaining {
+			rate, ok := providerRates[target]
+			if !ok {
+				targetErrors[target] = append(targetErrors[target], symbolNotFound(provider.Name, base, target))
+				missing = append(missing, target)
+				continue
+			}
+			rates[target] = rate
+		}
+		remaining = missing
+	}
+
+	if len(rates) == 0 && len(errs) == len(c.providers) {
+		return nil, joinProviderErrors(errs)
+	}
+
+	if len(remaining) != 0 {
+		partial := &PartialRatesError{Errors: make(map[string]error, len(remaining))}
+		for _, target := range remaining {
+			partial.Errors[target] = errors.Join(targetErrors[target]...)
+		}
+		return rates, partial
+	}
+
+	return rates, nil
+}
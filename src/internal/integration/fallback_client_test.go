@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockApiClient struct {
+	mock.Mock
+}
+
+func (m *mockApiClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	args := m.Called(base, targets)
+	return args.Get(0).(map[string]model.RateQuote), args.Error(1)
+}
+
+func TestFallbackClient_ShouldUseNextProviderWhenProviderFails(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := NewFallbackClient(namedClients(first, second)...)
+
+	quote := model.RateQuote{Rate: decimal.RequireFromString("0.85"), Provider: "second"}
+	first.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), errors.New("unavailable"))
+	second.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote{"EUR": quote}, nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]model.RateQuote{"EUR": quote}, rates)
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
+
+func TestFallbackClient_ShouldRequestOnlyMissingTargetsFromNextProvider(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := NewFallbackClient(namedClients(first, second)...)
+
+	eur := model.RateQuote{Rate: decimal.RequireFromString("0.85"), Provider: "first"}
+	mxn := model.RateQuote{Rate: decimal.RequireFromString("17.5"), Provider: "second"}
+	first.On("GetRates", "USD", []string{"EUR", "MXN"}).Return(map[string]model.RateQuote{"EUR": eur}, nil)
+	second.On("GetRates", "USD", []string{"MXN"}).Return(map[string]model.RateQuote{"MXN": mxn}, nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "MXN"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]model.RateQuote{"EUR": eur, "MXN": mxn}, rates)
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
+
+func TestFallbackClient_ShouldNotCallNextProviderWhenAllRatesReturned(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := NewFallbackClient(namedClients(first, second)...)
+
+	eur := model.RateQuote{Rate: decimal.RequireFromString("0.85"), Provider: "first"}
+	first.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote{"EUR": eur}, nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]model.RateQuote{"EUR": eur}, rates)
+	second.AssertNotCalled(t, "GetRates", mock.Anything, mock.Anything)
+}
+
+func TestFallbackClient_ShouldReturnErrorWhenAllProvidersFail(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := NewFallbackClient(namedClients(first, second)...)
+
+	firstError, secondError := errors.New("first error"), errors.New("second error")
+	first.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), firstError)
+	second.On("GetRates", "USD", []string{"EUR"}).Return(map[string]model.RateQuote(nil), secondError)
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, firstError)
+	assert.ErrorIs(t, err, secondError)
+}
+
+func TestFallbackClient_ShouldReturnUnsupportedTargetOnlyWhenAllProvidersHaveNoRate(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := NewFallbackClient(namedClients(first, second)...)
+
+	eur := model.RateQuote{Rate: decimal.RequireFromString("0.85"), Provider: "first"}
+	first.On("GetRates", "USD", []string{"EUR", "XXX"}).Return(map[string]model.RateQuote{"EUR": eur}, nil)
+	second.On("GetRates", "USD", []string{"XXX"}).Return(map[string]model.RateQuote{}, nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "XXX"})
+
+	var partial *PartialRatesError
+	assert.ErrorAs(t, err, &partial)
+	assert.Equal(t, map[string]model.RateQuote{"EUR": eur}, rates)
+	assert.True(t, IsPermanent(partial.Errors["XXX"]))
+	assert.Equal(t, "first: rate USD/XXX not found\nsecond: rate USD/XXX not found", partial.Errors["XXX"].Error())
+}
+
+func TestFallbackClient_ShouldReturnTransientErrorOfTargetMissingAfterProviderFailed(t *testing.T) {
+	first, second := new(mockApiClient), new(mockApiClient)
+	client := NewFallbackClient(namedClients(first, second)...)
+
+	eur := model.RateQuote{Rate: decimal.RequireFromString("0.85"), Provider: "first"}
+	first.On("GetRates", "USD", []string{"EUR", "MXN"}).Return(map[string]model.RateQuote{"EUR": eur}, nil)
+	second.On("GetRates", "USD", []string{"MXN"}).
+		Return(map[string]model.RateQuote(nil), &ProviderError{Provider: "second", Err: ErrUpstreamUnavailable})
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "MXN"})
+
+	var partial *PartialRatesError
+	assert.ErrorAs(t, err, &partial)
+	assert.Equal(t, map[string]model.RateQuote{"EUR": eur}, rates)
+	assert.ErrorIs(t, partial.Errors["MXN"], ErrSymbolUnsupported)
+	assert.ErrorIs(t, partial.Errors["MXN"], ErrUpstreamUnavailable)
+	assert.False(t, IsPermanent(partial.Errors["MXN"]))
+}
+
+func TestNewExchangeRateApiClient_ShouldRejectUnknownProvider(t *testing.T) {
+	_, _, err := NewExchangeRateApiClient(&config.Config{RateProviders: []string{"unknown"}}, nil)
+
+	assert.Error(t, err)
+}
+
+func TestNewExchangeRateApiClient_ShouldRejectConsensusWithLessProvidersThanMinQuotes(t *testing.T) {
+	config := &config.Config{
+		RateProviders:      []string{"currency-api"},
+		RateAggregation:    "consensus",
+		ConsensusMinQuotes: 2,
+	}
+
+	_, _, err := NewExchangeRateApiClient(config, nil)
+
+	assert.ErrorContains(t, err, "consensus requires 2 rate providers, 1 configured")
+}
+
+// namedClients names the providers by their order: first, second, third
+func namedClients(providers ...ExchangeRateApiClient) []NamedClient {
+	names := []string{"first", "second", "third"}
+	named := make([]NamedClient, 0, len(providers))
+	for i, provider := range providers {
+		named = append(named, NamedClient{Name: names[i], Client: provider})
+	}
+	return named
+}
@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FixerClient reads the Fixer api. Bases other than EUR require the paid plan
+type FixerClient struct {
+	client    *http.Client
+	baseUrl   string
+	accessKey string
+}
+
+type FixerResponse struct {
+	Success bool                   `json:"success"`
+	Base    string                 `json:"base"`
+	Date    string                 `json:"date"`
+	Rates   map[string]json.Number `json:"rates"`
+	Error   *ApiLayerError         `json:"error"`
+}
+
+func NewFixerClient(config *config.Config) *FixerClient {
+	return &FixerClient{
+		client:    &http.Client{Timeout: config.HttpClientTimeout},
+		baseUrl:   config.FixerBaseUrl,
+		accessKey: config.FixerApiKey,
+	}
+}
+
+const FixerProvider = "fixer.io"
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name: FixerProvider,
+		New: func(config *config.Config) (ExchangeRateApiClient, error) {
+			if config.FixerApiKey == "" {
+				return nil, fmt.Errorf("provider %s requires FIXER_API_KEY", FixerProvider)
+			}
+			return NewFixerClient(config), nil
+		},
+		Capabilities: ProviderCapabilities{Latest: true, Historical: true, MultiSymbol: true},
+	})
+}
+
+func (c *FixerClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	return c.getRates(ctx, "latest", base, targets)
+}
+
+func (c *FixerClient) GetRatesOn(ctx context.Context, base string, targets []string, date time.Time) (map[string]model.RateQuote, error) {
+	return c.getRates(ctx, date.UTC().Format(time.DateOnly), base, targets)
+}
+
+func (c *FixerClient) getRates(ctx context.Context, path string, base string, targets []string) (map[string]model.RateQuote, error) {
+	query := url.Values{}
+	query.Set("access_key", c.accessKey)
+	query.Set("base", base)
+	query.Set("symbols", strings.Join(targets, ","))
+	fullUrl := fmt.Sprintf("%s/%s?%s", c.baseUrl, path, query.Encode())
+
+	response := FixerResponse{}
+	err := getJson(ctx, c.client, FixerProvider, fullUrl, &response)
+	if response.Error != nil {
+		return nil, response.Error.providerError(FixerProvider)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.Success {
+		return nil, &ProviderError{Provider: FixerProvider, Message: "request was not successful"}
+	}
+
+	return parseRates(response.Rates, targets, FixerProvider)
+}
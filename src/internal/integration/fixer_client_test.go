@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixerClient_ShouldReturnRatesOfAllSymbols(t *testing.T) {
+	client := createFixerClient(t)
+
+	rates, err := client.GetRates(context.Background(), "EUR", []string{"USD", "MXN"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.089", rates["USD"].Rate.String())
+	assert.Equal(t, "18.1625", rates["MXN"].Rate.String())
+	assert.Equal(t, FixerProvider, rates["USD"].Provider)
+}
+
+func TestFixerClient_ShouldRequestDateEndpoint(t *testing.T) {
+	client := createFixerClient(t)
+
+	rates, err := client.GetRatesOn(context.Background(), "EUR", []string{"USD"}, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.089", rates["USD"].Rate.String())
+}
+
+func TestFixerClient_ShouldReturnErrorOfUnsuccessfulResponse(t *testing.T) {
+	client := createFixerClient(t)
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.EqualError(t, err, "fixer.io 105 base_currency_access_restricted: "+
+		"Access Restricted - Your current Subscription Plan does not support Source Currency Switching.")
+}
+
+// createFixerClient serves the EUR fixture for latest and 2024-03-15, other bases are restricted like on the free plan
+func createFixerClient(t *testing.T) *FixerClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "access-key", r.URL.Query().Get("access_key"))
+
+		if r.URL.Query().Get("base") != "EUR" {
+			http.ServeFile(w, r, "testdata/fixer-base-currency-restricted.json")
+			return
+		}
+
+		if r.URL.Path != "/latest" && r.URL.Path != "/2024-03-15" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/fixer-latest.json")
+	}))
+	t.Cleanup(server.Close)
+
+	return NewFixerClient(&config.Config{
+		FixerBaseUrl:      server.URL,
+		FixerApiKey:       "access-key",
+		HttpClientTimeout: time.Second,
+	})
+}
@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FrankfurterClient reads the Frankfurter api, which can be self-hosted for offline environments
+type FrankfurterClient struct {
+	client  *http.Client
+	baseUrl string
+}
+
+type FrankfurterResponse struct {
+	Base  string                 `json:"base"`
+	Date  string                 `json:"date"`
+	Rates map[string]json.Number `json:"rates"`
+	// Message is the error of not OK status
+	Message string `json:"message"`
+}
+
+func NewFrankfurterClient(config *config.Config) *FrankfurterClient {
+	return &FrankfurterClient{
+		client:  &http.Client{Timeout: config.HttpClientTimeout},
+		baseUrl: config.FrankfurterBaseUrl,
+	}
+}
+
+const FrankfurterProvider = "frankfurter"
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name: FrankfurterProvider,
+		New: func(config *config.Config) (ExchangeRateApiClient, error) {
+			return NewFrankfurterClient(config), nil
+		},
+		Capabilities: ProviderCapabilities{Latest: true, Historical: true, MultiSymbol: true},
+	})
+}
+
+// GetRates returns the latest rates of all targets in one request
+func (c *FrankfurterClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	return c.getRates(ctx, "latest", base, targets)
+}
+
+// GetRatesOn returns the rates published on the date or the last working day before it
+func (c *FrankfurterClient) GetRatesOn(ctx context.Context, base string, targets []string, date time.Time) (map[string]model.RateQuote, error) {
+	return c.getRates(ctx, date.UTC().Format(time.DateOnly), base, targets)
+}
+
+func (c *FrankfurterClient) getRates(ctx context.Context, path string, base string, targets []string) (map[string]model.RateQuote, error) {
+	query := url.Values{}
+	query.Set("base", base)
+	query.Set("symbols", strings.Join(targets, ","))
+	fullUrl := fmt.Sprintf("%s/%s?%s", c.baseUrl, path, query.Encode())
+
+	response := FrankfurterResponse{}
+	if err := getJson(ctx, c.client, FrankfurterProvider, fullUrl, &response); err != nil {
+		var providerError *ProviderError
+		if errors.As(err, &providerError) && response.Message != "" {
+			providerError.Message = response.Message
+		}
+		return nil, err
+	}
+
+	if response.Base != base {
+		return nil, fmt.Errorf("expected %s base in response, got %s", base, response.Base)
+	}
+
+	return parseRates(response.Rates, targets, FrankfurterProvider)
+}
@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrankfurterClient_ShouldReturnLatestRatesOfAllSymbols(t *testing.T) {
+	client := createFrankfurterClient(t)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "MXN", "CHF"})
+
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "0.91827", rates["EUR"].Rate.String())
+	assert.Equal(t, "16.6781", rates["MXN"].Rate.String())
+	assert.Equal(t, FrankfurterProvider, rates["EUR"].Provider)
+}
+
+func TestFrankfurterClient_ShouldReturnRatesOnDate(t *testing.T) {
+	client := createFrankfurterClient(t)
+
+	rates, err := client.GetRatesOn(context.Background(), "USD", []string{"EUR"}, time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.91474", rates["EUR"].Rate.String())
+}
+
+func TestFrankfurterClient_ShouldReturnErrorOfNotOkStatus(t *testing.T) {
+	client := createFrankfurterClient(t)
+
+	_, err := client.GetRates(context.Background(), "XXX", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrSymbolUnsupported)
+	assert.EqualError(t, err, "frankfurter status 404: not found")
+}
+
+// createFrankfurterClient serves the testdata fixtures of USD base
+func createFrankfurterClient(t *testing.T) *FrankfurterClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("base") != "USD" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		http.ServeFile(w, r, "testdata/frankfurter-"+r.URL.Path[1:]+".json")
+	}))
+	t.Cleanup(server.Close)
+
+	return NewFrankfurterClient(&config.Config{FrankfurterBaseUrl: server.URL, HttpClientTimeout: time.Second})
+}
@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// getJson decodes the response body with numbers kept as json.Number, so rates are not rounded to float64.
+// The body of not OK status is decoded too, so the caller can read the provider error payload.
+// Returns *ProviderError typed by the status, failed requests are ErrUpstreamUnavailable unless the context is done
+func getJson(ctx context.Context, client *http.Client, provider string, fullUrl string, response any) error {
+	resp, err := getContext(ctx, client, fullUrl)
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if err != nil {
+		return &ProviderError{Provider: provider, Message: err.Error(), Err: ErrUpstreamUnavailable}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Message: err.Error(), Err: ErrUpstreamUnavailable}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	decodeErr := dec.Decode(response)
+
+	if resp.StatusCode != http.StatusOK {
+		return &ProviderError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(body[:min(len(body), 512)])),
+			Err:        errorOfStatus(resp.StatusCode),
+		}
+	}
+
+	return decodeErr
+}
+
+// getContext returns errors without the url, since the query of some providers contains the api key
+func getContext(ctx context.Context, client *http.Client, fullUrl string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullUrl, nil)
+	if err != nil {
+		return nil, withoutUrl(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, withoutUrl(err)
+	}
+	return resp, nil
+}
+
+func withoutUrl(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// parseRates keeps the rates of the targets, targets without the rate are not in the result
+func parseRates(responseRates map[string]json.Number, targets []string, provider string) (map[string]model.RateQuote, error) {
+	rates := make(map[string]model.RateQuote, len(targets))
+	for _, target := range targets {
+		rate, ok := responseRates[target]
+		if !ok {
+			continue
+		}
+
+		value, err := decimal.NewFromString(rate.String())
+		if err != nil {
+			return nil, err
+		}
+		rates[target] = model.RateQuote{Rate: value, Provider: provider}
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OpenExchangeRatesClient reads the Open Exchange Rates api. Bases other than USD require the paid plan
+type OpenExchangeRatesClient struct {
+	client  *http.Client
+	baseUrl string
+	appId   string
+}
+
+type OpenExchangeRatesResponse struct {
+	Timestamp   uint64                 `json:"timestamp"`
+	Base        string                 `json:"base"`
+	Rates       map[string]json.Number `json:"rates"`
+	Error       bool                   `json:"error"`
+	Status      int                    `json:"status"`
+	Message     string                 `json:"message"`
+	Description string                 `json:"description"`
+}
+
+func NewOpenExchangeRatesClient(config *config.Config) *OpenExchangeRatesClient {
+	return &OpenExchangeRatesClient{
+		client:  &http.Client{Timeout: config.HttpClientTimeout},
+		baseUrl: config.OpenExchangeRatesBaseUrl,
+		appId:   config.OpenExchangeRatesAppId,
+	}
+}
+
+const OpenExchangeRatesProvider = "openexchangerates.org"
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name: OpenExchangeRatesProvider,
+		New: func(config *config.Config) (ExchangeRateApiClient, error) {
+			if config.OpenExchangeRatesAppId == "" {
+				return nil, fmt.Errorf("provider %s requires OPEN_EXCHANGE_RATES_APP_ID", OpenExchangeRatesProvider)
+			}
+			return NewOpenExchangeRatesClient(config), nil
+		},
+		Capabilities: ProviderCapabilities{Latest: true, Historical: true, MultiSymbol: true},
+	})
+}
+
+func (c *OpenExchangeRatesClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	return c.getRates(ctx, "latest.json", base, targets)
+}
+
+func (c *OpenExchangeRatesClient) GetRatesOn(ctx context.Context, base string, targets []string, date time.Time) (map[string]model.RateQuote, error) {
+	return c.getRates(ctx, fmt.Sprintf("historical/%s.json", date.UTC().Format(time.DateOnly)), base, targets)
+}
+
+func (c *OpenExchangeRatesClient) getRates(ctx context.Context, path string, base string, targets []string) (map[string]model.RateQuote, error) {
+	query := url.Values{}
+	query.Set("app_id", c.appId)
+	query.Set("base", base)
+	query.Set("symbols", strings.Join(targets, ","))
+	fullUrl := fmt.Sprintf("%s/%s?%s", c.baseUrl, path, query.Encode())
+
+	response := OpenExchangeRatesResponse{}
+	err := getJson(ctx, c.client, OpenExchangeRatesProvider, fullUrl, &response)
+	if response.Error {
+		return nil, &ProviderError{
+			Provider:   OpenExchangeRatesProvider,
+			StatusCode: response.Status,
+			Code:       response.Message,
+			Message:    response.Description,
+			Err:        openExchangeRatesError(response.Status, response.Message),
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRates(response.Rates, targets, OpenExchangeRatesProvider)
+}
+
+// openExchangeRatesError types the error by the message, the status is used for unknown messages
+func openExchangeRatesError(status int, message string) error {
+	switch message {
+	case "invalid_base", "not_found":
+		return ErrSymbolUnsupported
+	case "invalid_app_id", "missing_app_id", "not_allowed":
+		return ErrUnauthorized
+	case "access_restricted":
+		return ErrRateLimited
+	default:
+		return errorOfStatus(status)
+	}
+}
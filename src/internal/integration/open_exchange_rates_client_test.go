@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenExchangeRatesClient_ShouldReturnRatesOfAllSymbols(t *testing.T) {
+	client := createOpenExchangeRatesClient(t, "app-id")
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR", "MXN", "CHF"})
+
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "0.918275", rates["EUR"].Rate.String())
+	assert.Equal(t, OpenExchangeRatesProvider, rates["MXN"].Provider)
+}
+
+func TestOpenExchangeRatesClient_ShouldRequestHistoricalFile(t *testing.T) {
+	client := createOpenExchangeRatesClient(t, "app-id")
+
+	rates, err := client.GetRatesOn(context.Background(), "USD", []string{"EUR"}, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.918275", rates["EUR"].Rate.String())
+}
+
+func TestOpenExchangeRatesClient_ShouldReturnErrorOfInvalidAppId(t *testing.T) {
+	client := createOpenExchangeRatesClient(t, "invalid")
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.ErrorContains(t, err, "Invalid App ID provided")
+}
+
+// createOpenExchangeRatesClient serves the latest fixture for the latest and 2024-03-15 historical files
+func createOpenExchangeRatesClient(t *testing.T, appId string) *OpenExchangeRatesClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("app_id") != "app-id" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			body, _ := os.ReadFile("testdata/openexchangerates-invalid-app-id.json")
+			w.Write(body)
+			return
+		}
+
+		if r.URL.Path != "/latest.json" && r.URL.Path != "/historical/2024-03-15.json" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/openexchangerates-latest.json")
+	}))
+	t.Cleanup(server.Close)
+
+	return NewOpenExchangeRatesClient(&config.Config{
+		OpenExchangeRatesBaseUrl: server.URL,
+		OpenExchangeRatesAppId:   appId,
+		HttpClientTimeout:        time.Second,
+	})
+}
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrRateLimited         = errors.New("provider rate limit exceeded")
+	ErrUnauthorized        = errors.New("provider rejected the credentials")
+	ErrSymbolUnsupported   = errors.New("currency is not supported by the provider")
+	ErrUpstreamUnavailable = errors.New("provider is unavailable")
+)
+
+// ProviderError is the failed provider call. Err is one of the typed provider errors,
+// or nil when the failure is not recognized
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Code       string
+	Message    string
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	message := e.Provider
+	if e.StatusCode != 0 {
+		message += fmt.Sprintf(" status %d", e.StatusCode)
+	}
+	if e.Code != "" {
+		message += " " + e.Code
+	}
+	return message + ": " + e.Message
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// errorOfStatus returns the typed error of the http status, nil for statuses without the type
+func errorOfStatus(statusCode int) error {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusNotFound || statusCode == http.StatusUnprocessableEntity:
+		return ErrSymbolUnsupported
+	case statusCode >= http.StatusInternalServerError:
+		return ErrUpstreamUnavailable
+	default:
+		return nil
+	}
+}
+
+// ApiLayerError is the error payload of the apilayer apis, Fixer and exchangeratesapi.io
+type ApiLayerError struct {
+	Code int    `json:"code"`
+	Type string `json:"type"`
+	Info string `json:"info"`
+}
+
+func (e *ApiLayerError) providerError(provider string) *ProviderError {
+	var err error
+	switch e.Code {
+	case 101, 102, 105:
+		// Invalid access key, inactive account or the endpoint is not in the subscription plan
+		err = ErrUnauthorized
+	case 104:
+		// Monthly usage limit reached
+		err = ErrRateLimited
+	case 106, 201, 202:
+		// No results, invalid base or invalid symbols
+		err = ErrSymbolUnsupported
+	}
+
+	return &ProviderError{
+		Provider: provider,
+		Code:     fmt.Sprintf("%d %s", e.Code, e.Type),
+		Message:  e.Info,
+		Err:      err,
+	}
+}
+
+// symbolNotFound is the error of the target the provider response has no rate of
+func symbolNotFound(provider string, from string, to string) error {
+	return &ProviderError{
+		Provider: provider,
+		Message:  fmt.Sprintf("rate %s/%s not found", from, to),
+		Err:      ErrSymbolUnsupported,
+	}
+}
+
+// IsPermanent tells whether the retry of the call cannot succeed: the credentials are rejected or the currency
+// is not supported. Joined errors are permanent only when all of them are, so one failed fallback provider
+// does not fail the update
+func IsPermanent(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, err := range errs {
+			if !IsPermanent(err) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrSymbolUnsupported)
+}
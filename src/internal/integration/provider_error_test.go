@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJson_ShouldTypeErrorByStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrSymbolUnsupported},
+		{http.StatusBadGateway, ErrUpstreamUnavailable},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"failed"}`, test.status)
+		}))
+
+		response := map[string]any{}
+		err := getJson(context.Background(), &http.Client{Timeout: time.Second}, "provider", server.URL, &response)
+		server.Close()
+
+		var providerError *ProviderError
+		assert.ErrorAs(t, err, &providerError)
+		assert.ErrorIs(t, err, test.err)
+		assert.Equal(t, test.status, providerError.StatusCode)
+		assert.Equal(t, "failed", response["message"])
+	}
+}
+
+func TestGetJson_ShouldReturnUpstreamUnavailableWhenRequestFails(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := getJson(context.Background(), &http.Client{Timeout: time.Second}, "provider", server.URL, &map[string]any{})
+
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+}
+
+func TestGetJson_ShouldNotReturnApiKeyWhenRequestFails(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := getJson(context.Background(), &http.Client{Timeout: time.Second}, "provider",
+		server.URL+"/latest?access_key=secret-key", &map[string]any{})
+
+	var providerError *ProviderError
+	assert.ErrorAs(t, err, &providerError)
+	assert.NotContains(t, providerError.Error(), "secret-key")
+	assert.NotContains(t, providerError.Error(), server.URL)
+}
+
+func TestGetJson_ShouldReturnContextErrorWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := getJson(ctx, &http.Client{Timeout: time.Second}, "provider", server.URL, &map[string]any{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrUpstreamUnavailable)
+}
+
+func TestApiLayerError_ShouldTypeErrorByCode(t *testing.T) {
+	assert.ErrorIs(t, (&ApiLayerError{Code: 101, Type: "invalid_access_key"}).providerError("fixer.io"), ErrUnauthorized)
+	assert.ErrorIs(t, (&ApiLayerError{Code: 104, Type: "usage_limit_reached"}).providerError("fixer.io"), ErrRateLimited)
+	assert.ErrorIs(t, (&ApiLayerError{Code: 202, Type: "invalid_currency_codes"}).providerError("fixer.io"), ErrSymbolUnsupported)
+	assert.NoError(t, errors.Unwrap((&ApiLayerError{Code: 301, Type: "invalid_date"}).providerError("fixer.io")))
+}
+
+func TestIsPermanent_ShouldRequireAllJoinedErrorsPermanent(t *testing.T) {
+	unauthorized := &ProviderError{Provider: "first", Err: ErrUnauthorized}
+	unsupported := &ProviderError{Provider: "second", Err: ErrSymbolUnsupported}
+	unavailable := &ProviderError{Provider: "third", Err: ErrUpstreamUnavailable}
+
+	assert.True(t, IsPermanent(unauthorized))
+	assert.True(t, IsPermanent(errors.Join(unauthorized, unsupported)))
+	assert.False(t, IsPermanent(errors.Join(unauthorized, unavailable)))
+	assert.False(t, IsPermanent(&ProviderError{Provider: "first", Err: ErrRateLimited}))
+	assert.False(t, IsPermanent(errors.New("unknown")))
+}
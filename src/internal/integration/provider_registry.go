@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// HistoricalRateClient is implemented by providers with the historical capability
+type HistoricalRateClient interface {
+	// GetRatesOn returns rates published on the date or the last working day before it
+	GetRatesOn(ctx context.Context, base string, targets []string, date time.Time) (map[string]model.RateQuote, error)
+}
+
+// ProviderCapabilities tell which lookups the provider supports
+type ProviderCapabilities struct {
+	Latest      bool
+	Historical  bool
+	MultiSymbol bool
+}
+
+// ProviderRegistration is the provider known by the name in RATE_PROVIDERS
+type ProviderRegistration struct {
+	Name         string
+	New          func(config *config.Config) (ExchangeRateApiClient, error)
+	Capabilities ProviderCapabilities
+}
+
+var providerRegistry = make(map[string]ProviderRegistration)
+
+// RegisterProvider adds the provider to the registry, every client registers itself in init
+func RegisterProvider(registration ProviderRegistration) {
+	if _, ok := providerRegistry[registration.Name]; ok {
+		panic(fmt.Sprintf("rate provider %s registered twice", registration.Name))
+	}
+	providerRegistry[registration.Name] = registration
+}
+
+func LookupProvider(name string) (ProviderRegistration, bool) {
+	registration, ok := providerRegistry[name]
+	return registration, ok
+}
+
+// RegisteredProviders returns the registrations ordered by name
+func RegisteredProviders() []ProviderRegistration {
+	registrations := make([]ProviderRegistration, 0, len(providerRegistry))
+	for _, name := range slices.Sorted(maps.Keys(providerRegistry)) {
+		registrations = append(registrations, providerRegistry[name])
+	}
+	return registrations
+}
+
+// newProvider creates the registered provider. The worker asks for all targets of the base at once,
+// so the provider must support the latest rates of multiple symbols
+func newProvider(config *config.Config, name string) (ExchangeRateApiClient, error) {
+	registration, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown rate provider %s", name)
+	}
+
+	if !registration.Capabilities.Latest {
+		return nil, fmt.Errorf("rate provider %s does not support latest rates", name)
+	}
+
+	if !registration.Capabilities.MultiSymbol {
+		return nil, fmt.Errorf("rate provider %s does not support multiple symbols", name)
+	}
+
+	return registration.New(config)
+}
@@ -0,0 +1,42 @@
+package integration
+
+import (
+	"exchange-rates-service/src/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredProviders_ShouldImplementTheirCapabilities(t *testing.T) {
+	config := &config.Config{
+		ExchangeIoApiKey:       "key",
+		OpenExchangeRatesAppId: "key",
+		FixerApiKey:            "key",
+	}
+
+	registrations := RegisteredProviders()
+
+	names := make([]string, 0, len(registrations))
+	for _, registration := range registrations {
+		names = append(names, registration.Name)
+
+		provider, err := registration.New(config)
+		require.NoError(t, err, registration.Name)
+
+		_, historical := provider.(HistoricalRateClient)
+		assert.Equal(t, registration.Capabilities.Historical, historical, registration.Name)
+	}
+
+	assert.Equal(t, []string{
+		CurrencyApiProvider, EcbProvider, ExchangeRatesApiIoProvider, FixerProvider, FrankfurterProvider, OpenExchangeRatesProvider,
+	}, names)
+}
+
+func TestNewProvider_ShouldRequireApiKey(t *testing.T) {
+	for _, name := range []string{ExchangeRatesApiIoProvider, OpenExchangeRatesProvider, FixerProvider} {
+		_, err := newProvider(&config.Config{}, name)
+
+		assert.Error(t, err, name)
+	}
+}
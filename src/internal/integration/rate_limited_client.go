@@ -0,0 +1,212 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExhausted is returned without calling the provider when its monthly quota is near the limit
+var ErrQuotaExhausted = errors.New("provider quota is exhausted")
+
+// QuotaCounter persists the monthly usage of the providers, so the quota is shared by all workers
+type QuotaCounter interface {
+	// ReserveProviderRequest counts the request if the usage is below maxRequests, returns the usage and whether it was counted
+	ReserveProviderRequest(ctx context.Context, provider string, period time.Time, maxRequests int) (int, bool, error)
+	GetProviderUsage(ctx context.Context, provider string, period time.Time) (int, error)
+}
+
+// RateLimitedClient spaces the provider calls by the token bucket and counts them in the persisted monthly usage.
+// Calls stop when the usage reaches the quota without the reserve, so the fallback switches to the next provider
+type RateLimitedClient struct {
+	provider string
+	client   ExchangeRateApiClient
+	usage    QuotaCounter
+	quota    int
+	reserve  int
+	bucket   *tokenBucket
+	now      func() time.Time
+	sleep    func(ctx context.Context, delay time.Duration) error
+
+	// exhaustedPeriod is the month the quota was used up in, the usage only grows until the next month
+	mutex           sync.Mutex
+	exhaustedPeriod time.Time
+}
+
+func NewRateLimitedClient(
+	config *config.Config,
+	provider string,
+	client ExchangeRateApiClient,
+	usage QuotaCounter) *RateLimitedClient {
+
+	quota := config.ProviderMonthlyQuotas[provider]
+	rateLimitedClient := &RateLimitedClient{
+		provider: provider,
+		client:   client,
+		usage:    usage,
+		quota:    quota,
+		reserve:  quota * config.ProviderQuotaReserve / 100,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+
+	if requestsPerMinute := config.ProviderRequestsPerMin[provider]; requestsPerMinute > 0 {
+		rateLimitedClient.bucket = &tokenBucket{
+			interval: time.Minute / time.Duration(requestsPerMinute),
+			capacity: float64(max(requestsPerMinute/60, 1)),
+			tokens:   float64(max(requestsPerMinute/60, 1)),
+		}
+	}
+
+	return rateLimitedClient
+}
+
+func (c *RateLimitedClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.client.GetRates(ctx, base, targets)
+}
+
+// Quota returns the usage of the current month, nil when the provider has no quota
+func (c *RateLimitedClient) Quota(ctx context.Context) (*model.ProviderQuota, error) {
+	if c.quota <= 0 {
+		return nil, nil
+	}
+
+	period := monthOf(c.now())
+	used, err := c.usage.GetProviderUsage(ctx, c.provider, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ProviderQuota{
+		Provider:  c.provider,
+		Period:    period,
+		Used:      used,
+		Limit:     c.quota,
+		Reserve:   c.reserve,
+		Remaining: max(c.quota-used, 0),
+	}, nil
+}
+
+// acquire fails at once when the quota of the month is known to be used up, so the exhausted provider does not
+// wait for tokens. Otherwise it waits for the token until the context is done and then counts the request
+// in the monthly usage, so the cancelled wait does not use the quota
+func (c *RateLimitedClient) acquire(ctx context.Context) error {
+	maxRequests := c.quota - c.reserve
+	if c.quota > 0 {
+		if maxRequests <= 0 {
+			return fmt.Errorf("%s: %w, the reserve takes the whole quota", c.provider, ErrQuotaExhausted)
+		}
+
+		if c.isExhausted(monthOf(c.now())) {
+			return fmt.Errorf("%s: %w, %d requests used", c.provider, ErrQuotaExhausted, maxRequests)
+		}
+	}
+
+	if c.bucket != nil {
+		if err := c.sleep(ctx, c.bucket.take(c.now())); err != nil {
+			return err
+		}
+	}
+
+	if c.quota > 0 {
+		period := monthOf(c.now())
+		used, reserved, err := c.usage.ReserveProviderRequest(ctx, c.provider, period, maxRequests)
+		if err != nil {
+			return err
+		}
+
+		if !reserved {
+			c.setExhausted(period)
+			return fmt.Errorf("%s: %w, %d of %d requests used", c.provider, ErrQuotaExhausted, used, maxRequests)
+		}
+	}
+
+	return nil
+}
+
+func (c *RateLimitedClient) isExhausted(period time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.exhaustedPeriod.Equal(period)
+}
+
+func (c *RateLimitedClient) setExhausted(period time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.exhaustedPeriod = period
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tokenBucket refills one token per interval up to the capacity.
+// Tokens taken from the empty bucket are reserved in advance, so concurrent callers wait in turn
+type tokenBucket struct {
+	mutex     sync.Mutex
+	interval  time.Duration
+	capacity  float64
+	tokens    float64
+	updatedAt time.Time
+}
+
+// take returns the delay until the taken token is available
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.updatedAt.IsZero() {
+		b.tokens = min(b.tokens+float64(now.Sub(b.updatedAt))/float64(b.interval), b.capacity)
+	}
+	b.updatedAt = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// RateLimitedClients are the limiters of all configured providers
+type RateLimitedClients []*RateLimitedClient
+
+// Quotas returns the usage of providers with the monthly quota
+func (clients RateLimitedClients) Quotas(ctx context.Context) ([]model.ProviderQuota, error) {
+	quotas := make([]model.ProviderQuota, 0, len(clients))
+	for _, client := range clients {
+		quota, err := client.Quota(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if quota != nil {
+			quotas = append(quotas, *quota)
+		}
+	}
+	return quotas, nil
+}
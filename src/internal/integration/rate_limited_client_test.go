@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUsageRepository struct {
+	mock.Mock
+}
+
+func (m *mockUsageRepository) ReserveProviderRequest(ctx context.Context, provider string, period time.Time, maxRequests int) (int, bool, error) {
+	args := m.Called(provider, period, maxRequests)
+	return args.Int(0), args.Bool(1), args.Error(2)
+}
+
+func (m *mockUsageRepository) GetProviderUsage(ctx context.Context, provider string, period time.Time) (int, error) {
+	args := m.Called(provider, period)
+	return args.Int(0), args.Error(1)
+}
+
+var january = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestRateLimitedClient_ShouldCallProviderWhenRequestReserved(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, _ := createRateLimitedClient(provider, usage)
+
+	usage.On("ReserveProviderRequest", "provider", january, 950).Return(10, true, nil)
+	provider.On("GetRates", "USD", []string{"EUR"}).Return(quotes("provider", "EUR", "0.85"), nil)
+
+	rates, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, rates, "EUR")
+	usage.AssertExpectations(t)
+	provider.AssertExpectations(t)
+}
+
+func TestRateLimitedClient_ShouldNotCallProviderWhenQuotaExhausted(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, _ := createRateLimitedClient(provider, usage)
+
+	usage.On("ReserveProviderRequest", "provider", january, 950).Return(950, false, nil)
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, ErrQuotaExhausted)
+	assert.EqualError(t, err, "provider: provider quota is exhausted, 950 of 950 requests used")
+	provider.AssertNotCalled(t, "GetRates", mock.Anything, mock.Anything)
+}
+
+func TestRateLimitedClient_ShouldNotWaitForTokenWhenQuotaKnownExhausted(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, delays := createRateLimitedClient(provider, usage)
+
+	usage.On("ReserveProviderRequest", "provider", january, 950).Return(950, false, nil).Once()
+
+	for range 3 {
+		_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+		assert.ErrorIs(t, err, ErrQuotaExhausted)
+	}
+
+	assert.Equal(t, []time.Duration{0}, *delays)
+	usage.AssertNumberOfCalls(t, "ReserveProviderRequest", 1)
+	provider.AssertNotCalled(t, "GetRates", mock.Anything, mock.Anything)
+}
+
+func TestRateLimitedClient_ShouldWaitForToken(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, delays := createRateLimitedClient(provider, usage)
+
+	usage.On("ReserveProviderRequest", "provider", january, 950).Return(10, true, nil)
+	provider.On("GetRates", "USD", []string{"EUR"}).Return(quotes("provider", "EUR", "0.85"), nil)
+
+	for range 3 {
+		client.GetRates(context.Background(), "USD", []string{"EUR"})
+	}
+
+	// 30 requests per minute are spaced by 2 seconds, the first one is taken from the full bucket
+	assert.Equal(t, []time.Duration{0, 2 * time.Second, 4 * time.Second}, *delays)
+}
+
+func TestRateLimitedClient_ShouldNotUseQuotaWhenTokenWaitCancelled(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, _ := createRateLimitedClient(provider, usage)
+	client.sleep = func(ctx context.Context, delay time.Duration) error { return context.Canceled }
+
+	_, err := client.GetRates(context.Background(), "USD", []string{"EUR"})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	usage.AssertNotCalled(t, "ReserveProviderRequest", mock.Anything, mock.Anything, mock.Anything)
+	provider.AssertNotCalled(t, "GetRates", mock.Anything, mock.Anything)
+}
+
+func TestRateLimitedClient_ShouldReportRemainingQuota(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, _ := createRateLimitedClient(provider, usage)
+
+	usage.On("GetProviderUsage", "provider", january).Return(960, nil)
+
+	quotas, err := RateLimitedClients{client}.Quotas(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ProviderQuota{
+		{Provider: "provider", Period: january, Used: 960, Limit: 1000, Reserve: 50, Remaining: 40},
+	}, quotas)
+}
+
+func TestCircuitBreakerClient_ShouldNotCountExhaustedQuotaAsFailure(t *testing.T) {
+	provider, usage := new(mockApiClient), new(mockUsageRepository)
+	client, _ := createRateLimitedClient(provider, usage)
+	breaker, _ := createCircuitBreaker(client)
+
+	usage.On("ReserveProviderRequest", "provider", january, 950).Return(950, false, nil)
+
+	for range 3 {
+		_, err := breaker.GetRates(context.Background(), "USD", []string{"EUR"})
+		assert.ErrorIs(t, err, ErrQuotaExhausted)
+	}
+
+	assert.Equal(t, model.CircuitClosed, breaker.Status().State)
+}
+
+// createRateLimitedClient allows 30 requests per minute and 1000 requests per month with 5% reserve,
+// the returned delays are the waits for tokens
+func createRateLimitedClient(provider ExchangeRateApiClient, usage *mockUsageRepository) (*RateLimitedClient, *[]time.Duration) {
+	config := &config.Config{
+		ProviderRequestsPerMin: map[string]int{"provider": 30},
+		ProviderMonthlyQuotas:  map[string]int{"provider": 1000},
+		ProviderQuotaReserve:   5,
+	}
+
+	delays := make([]time.Duration, 0)
+	client := NewRateLimitedClient(config, "provider", provider, usage)
+	client.now = func() time.Time { return january.Add(time.Hour) }
+	client.sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return nil
+	}
+	return client, &delays
+}
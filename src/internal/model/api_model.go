@@ -13,9 +13,147 @@ type StartUpdateRateResponse struct {
 	UpdateId string `json:"updateId"`
 }
 
-type GetRateResponse struct {
+type StartUpdateRateBatchRequest struct {
+	Pairs   []StartUpdateRateRequest `json:"pairs"`
+	Base    string                   `json:"base"`
+	Targets []string                 `json:"targets"`
+}
+
+type StartUpdateRateBatchResponse struct {
+	Updates map[string]StartUpdateRateBatchItem `json:"updates"`
+}
+
+type StartUpdateRateBatchItem struct {
+	UpdateId *string `json:"updateId,omitempty"`
+	Error    *string `json:"error,omitempty"`
+}
+
+type GetLastRateBatchRequest struct {
+	Pairs []StartUpdateRateRequest `json:"pairs"`
+}
+
+type GetLastRateBatchResponse struct {
+	Rates []LastRateBatchItem `json:"rates"`
+}
+
+type LastRateBatchItem struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Found      bool    `json:"found"`
 	Rate       *string `json:"rate"`
 	UpdateTime *string `json:"updateTime"`
+	Provider   *string `json:"provider,omitempty"`
+	Error      *string `json:"error,omitempty"`
+}
+
+type GetRateUpdateResponse struct {
+	Status       string  `json:"status"`
+	CreatedAt    *string `json:"createdAt"`
+	Attempts     int     `json:"attempts"`
+	ErrorMessage *string `json:"errorMessage"`
+	Rate         *string `json:"rate"`
+	UpdateTime   *string `json:"updateTime"`
+	Provider     *string `json:"provider"`
+}
+
+type GetRateResponse struct {
+	Rate       *string           `json:"rate"`
+	UpdateTime *string           `json:"updateTime"`
+	Provider   *string           `json:"provider,omitempty"`
+	Derived    *string           `json:"derived,omitempty"`
+	Legs       []RateLegResponse `json:"legs,omitempty"`
+}
+
+type RateLegResponse struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Rate       string `json:"rate"`
+	UpdateTime string `json:"updateTime"`
+	Provider   string `json:"provider,omitempty"`
+	Inverted   bool   `json:"inverted"`
+}
+
+type ConvertResponse struct {
+	From         string            `json:"from"`
+	To           string            `json:"to"`
+	Amount       string            `json:"amount"`
+	Result       string            `json:"result"`
+	RoundingMode string            `json:"roundingMode"`
+	Rate         string            `json:"rate"`
+	UpdateTime   string            `json:"updateTime"`
+	Provider     *string           `json:"provider,omitempty"`
+	Derived      *string           `json:"derived,omitempty"`
+	Legs         []RateLegResponse `json:"legs,omitempty"`
+}
+
+type GetRateHistoryResponse struct {
+	Rates      []GetRateResponse `json:"rates"`
+	NextCursor *string           `json:"nextCursor"`
+}
+
+type SetCurrencyActiveRequest struct {
+	Code string `json:"code"`
+}
+
+type CurrencyResponse struct {
+	Code        string `json:"code"`
+	NumericCode string `json:"numericCode"`
+	MinorUnits  int32  `json:"minorUnits"`
+	Name        string `json:"name"`
+	Active      bool   `json:"active"`
+}
+
+type GetCurrenciesResponse struct {
+	Currencies []CurrencyRatesResponse `json:"currencies"`
+}
+
+type CurrencyRatesResponse struct {
+	Code        string                 `json:"code"`
+	NumericCode string                 `json:"numericCode"`
+	MinorUnits  int32                  `json:"minorUnits"`
+	Name        string                 `json:"name"`
+	Pairs       []CurrencyPairResponse `json:"pairs"`
+}
+
+type CurrencyPairResponse struct {
+	To         string `json:"to"`
+	UpdateTime string `json:"updateTime"`
+}
+
+type GetCircuitBreakersResponse struct {
+	Breakers []CircuitBreakerResponse `json:"breakers"`
+}
+
+type CircuitBreakerResponse struct {
+	Provider string  `json:"provider"`
+	State    string  `json:"state"`
+	Failures int     `json:"failures"`
+	OpenedAt *string `json:"openedAt"`
+}
+
+type GetProvidersResponse struct {
+	Providers []ProviderResponse `json:"providers"`
+}
+
+type ProviderResponse struct {
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+	Latest      bool   `json:"latest"`
+	Historical  bool   `json:"historical"`
+	MultiSymbol bool   `json:"multiSymbol"`
+}
+
+type GetProviderQuotasResponse struct {
+	Quotas []ProviderQuotaResponse `json:"quotas"`
+}
+
+type ProviderQuotaResponse struct {
+	Provider  string `json:"provider"`
+	Period    string `json:"period"`
+	Used      int    `json:"used"`
+	Limit     int    `json:"limit"`
+	Reserve   int    `json:"reserve"`
+	Remaining int    `json:"remaining"`
 }
 
 func (r *StartUpdateRateRequest) Validate() error {
@@ -28,3 +166,69 @@ func (r *StartUpdateRateRequest) Validate() error {
 
 	return nil
 }
+
+func (r *StartUpdateRateBatchRequest) Validate() error {
+	if len(r.Pairs) == 0 && r.Base == "" {
+		return internal.NewBadRequestError("pairs or base currency should be set")
+	}
+
+	if r.Base != "" && len(r.Targets) == 0 {
+		return internal.NewBadRequestError("targets are not set")
+	}
+
+	if r.Base == "" && len(r.Targets) != 0 {
+		return internal.NewBadRequestError("base currency is not set")
+	}
+
+	for _, pair := range r.Pairs {
+		if err := pair.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CurrencyPairs returns requested pairs followed by the base currency pairs
+func (r *StartUpdateRateBatchRequest) CurrencyPairs() []CurrencyPair {
+	pairs := make([]CurrencyPair, 0, len(r.Pairs)+len(r.Targets))
+	for _, pair := range r.Pairs {
+		pairs = append(pairs, CurrencyPair{From: pair.From, To: pair.To})
+	}
+
+	for _, target := range r.Targets {
+		pairs = append(pairs, CurrencyPair{From: r.Base, To: target})
+	}
+
+	return pairs
+}
+
+func (r *GetLastRateBatchRequest) Validate() error {
+	if len(r.Pairs) == 0 {
+		return internal.NewBadRequestError("pairs are not set")
+	}
+
+	for _, pair := range r.Pairs {
+		if err := pair.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *GetLastRateBatchRequest) CurrencyPairs() []CurrencyPair {
+	pairs := make([]CurrencyPair, 0, len(r.Pairs))
+	for _, pair := range r.Pairs {
+		pairs = append(pairs, CurrencyPair{From: pair.From, To: pair.To})
+	}
+	return pairs
+}
+
+func (r *SetCurrencyActiveRequest) Validate() error {
+	if r.Code == "" {
+		return internal.NewBadRequestError("currency code is not set")
+	}
+
+	return nil
+}
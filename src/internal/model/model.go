@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -9,6 +10,44 @@ import (
 type ExchangeRate struct {
 	Rate           *decimal.Decimal
 	UpdateDateTime *time.Time
+	Provider       string
+	Derived        RateDerivation
+	Legs           []ExchangeRateLeg
+}
+
+// RateQuote is the rate returned by the rate provider.
+// Consensus rate keeps the quotes of all providers in Sources and the spread of the accepted quotes
+type RateQuote struct {
+	Rate     decimal.Decimal
+	Provider string
+	Sources  []ProviderQuote
+	Spread   *decimal.Decimal
+}
+
+// ProviderQuote is the quote used to build the consensus rate. Rejected quotes deviated too far from the median
+type ProviderQuote struct {
+	Provider string
+	Rate     decimal.Decimal
+	Rejected bool
+}
+
+// RateDerivation shows how the rate was built when the pair is not stored. Empty for stored rates
+type RateDerivation string
+
+const (
+	DerivationInverse RateDerivation = "inverse"
+	DerivationCross   RateDerivation = "cross"
+)
+
+// ExchangeRateLeg is a stored rate used to build a cross rate.
+// Inverted legs are stored in the opposite direction, their Rate is already inverted
+type ExchangeRateLeg struct {
+	FromCurrency   string
+	ToCurrency     string
+	Rate           decimal.Decimal
+	UpdateDateTime time.Time
+	Provider       string
+	Inverted       bool
 }
 
 type ExchangeRateDbo struct {
@@ -16,6 +55,7 @@ type ExchangeRateDbo struct {
 	ToCurrency   string
 	RateValue    *decimal.Decimal
 	UpdateTime   *time.Time
+	Provider     *string
 }
 
 type ExchangeRateUpdateStatus int
@@ -26,6 +66,19 @@ const (
 	StatusError
 )
 
+func (status ExchangeRateUpdateStatus) String() string {
+	switch status {
+	case StatusUpdating:
+		return "updating"
+	case StatusDone:
+		return "done"
+	case StatusError:
+		return "error"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(status))
+	}
+}
+
 type ExchangeRateUpdateDbo struct {
 	Id           string
 	FromCurrency string
@@ -33,4 +86,148 @@ type ExchangeRateUpdateDbo struct {
 	Status       ExchangeRateUpdateStatus
 	RateValue    *decimal.Decimal
 	UpdateTime   *time.Time
+	CreatedAt    *time.Time
+	Attempts     int
+	ErrorMessage *string
+	ClaimedBy    string
+	Provider     *string
+	Sources      []ProviderQuote
+	Spread       *decimal.Decimal
+}
+
+// RateUpdate is the state of the update started by StartUpdateRate. Rate is empty until the update is done
+type RateUpdate struct {
+	ExchangeRate
+	Status       ExchangeRateUpdateStatus
+	CreatedAt    *time.Time
+	Attempts     int
+	ErrorMessage *string
+}
+
+// ReclaimResult counts the updates abandoned by workers. Requeued updates are picked again, failed get the error status
+type ReclaimResult struct {
+	Requeued int
+	Failed   int
+}
+
+type CircuitBreakerState string
+
+const (
+	CircuitClosed   CircuitBreakerState = "closed"
+	CircuitOpen     CircuitBreakerState = "open"
+	CircuitHalfOpen CircuitBreakerState = "half-open"
+)
+
+// CircuitBreakerStatus is the state of the breaker around the rate provider
+type CircuitBreakerStatus struct {
+	Provider string
+	State    CircuitBreakerState
+	Failures int
+	OpenedAt *time.Time
+}
+
+// ProviderQuota is the monthly request usage of the paid rate provider.
+// Requests stop when the usage reaches the limit without the reserve
+type ProviderQuota struct {
+	Provider  string
+	Period    time.Time
+	Used      int
+	Limit     int
+	Reserve   int
+	Remaining int
+}
+
+type ExchangeRateHistoryDbo struct {
+	Id           int64
+	UpdateId     string
+	FromCurrency string
+	ToCurrency   string
+	RateValue    *decimal.Decimal
+	UpdateTime   *time.Time
+	Provider     *string
+}
+
+type RateHistoryCursor struct {
+	UpdateTime time.Time
+	Id         int64
+}
+
+type RateHistoryPage struct {
+	Rates      []ExchangeRate
+	NextCursor *RateHistoryCursor
+}
+
+type Currency struct {
+	Code        string
+	NumericCode string
+	MinorUnits  int32
+	Name        string
+	Active      bool
+}
+
+type CurrencyPair struct {
+	From string
+	To   string
+}
+
+func (pair CurrencyPair) String() string {
+	return pair.From + "/" + pair.To
+}
+
+// RateUpdateResult is the result of starting the update of one pair in a batch. Either UpdateId or Error is set
+type RateUpdateResult struct {
+	UpdateId string
+	Error    error
+}
+
+// LastRateResult is the last rate of one pair in a batch. Rate is empty when the pair has no stored rate.
+// Error is set when the pair did not pass validation
+type LastRateResult struct {
+	Pair  CurrencyPair
+	Rate  ExchangeRate
+	Error error
+}
+
+// CurrencyRates is the currency with the pairs from it that have a stored rate
+type CurrencyRates struct {
+	Currency Currency
+	Rates    []ExchangeRateDbo
+}
+
+type RoundingMode string
+
+const (
+	RoundingHalfEven RoundingMode = "half-even"
+	RoundingHalfUp   RoundingMode = "half-up"
+	RoundingTruncate RoundingMode = "truncate"
+)
+
+func ParseRoundingMode(value string) (RoundingMode, error) {
+	mode := RoundingMode(value)
+	switch mode {
+	case RoundingHalfEven, RoundingHalfUp, RoundingTruncate:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown rounding mode %s", value)
+	}
+}
+
+// Round rounds value to the given number of decimal places. Half-up rounds half away from zero
+func (mode RoundingMode) Round(value decimal.Decimal, places int32) decimal.Decimal {
+	switch mode {
+	case RoundingHalfUp:
+		return value.Round(places)
+	case RoundingTruncate:
+		return value.Truncate(places)
+	default:
+		return value.RoundBank(places)
+	}
+}
+
+type Conversion struct {
+	Amount       decimal.Decimal
+	Result       decimal.Decimal
+	MinorUnits   int32
+	RoundingMode RoundingMode
+	Rate         ExchangeRate
 }
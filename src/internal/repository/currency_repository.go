@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"exchange-rates-service/src/internal/model"
+	"exchange-rates-service/src/internal/storage"
+)
+
+type CurrencyRepository interface {
+	GetCurrencies(ctx context.Context) ([]model.Currency, error)
+	SetCurrencyActive(ctx context.Context, code string, active bool) (model.Currency, error)
+}
+
+type PostgresCurrencyRepository struct {
+	currencyStorage storage.CurrencyStorage
+}
+
+func NewCurrencyRepository(currencyStorage storage.CurrencyStorage) *PostgresCurrencyRepository {
+	return &PostgresCurrencyRepository{currencyStorage: currencyStorage}
+}
+
+func (r *PostgresCurrencyRepository) GetCurrencies(ctx context.Context) ([]model.Currency, error) {
+	return r.currencyStorage.GetCurrencies(ctx)
+}
+
+func (r *PostgresCurrencyRepository) SetCurrencyActive(ctx context.Context, code string, active bool) (model.Currency, error) {
+	currency, err := r.currencyStorage.SetActive(ctx, code, active)
+	if err != nil {
+		return model.Currency{}, err
+	}
+	return *currency, nil
+}
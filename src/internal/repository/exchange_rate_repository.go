@@ -7,82 +7,139 @@ import (
 	"exchange-rates-service/src/internal/storage"
 
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
 
 	"time"
 )
 
 type ExchangeRateRepository interface {
-	GetOrCreateRateUpdate(from string, to string) (string, error)
-	GetRateUpdate(updateId string) (model.ExchangeRate, error)
-	GetRatesForUpdate(fetchSize int) ([]model.ExchangeRateUpdateDbo, error)
-	SetUpdateError(updateId string) error
-	UpdateRate(updateId string, from string, to string, rate decimal.Decimal) error
-	GetLastRate(from string, to string) (model.ExchangeRate, error)
+	GetOrCreateRateUpdate(ctx context.Context, from string, to string) (string, error)
+	GetOrCreateRateUpdates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]string, error)
+	GetRateUpdate(ctx context.Context, updateId string) (model.RateUpdate, error)
+	GetRatesForUpdate(ctx context.Context, workerId string, leaseDuration time.Duration, fetchSize int) ([]model.ExchangeRateUpdateDbo, error)
+	SetUpdateError(ctx context.Context, updateId string, workerId string, errorMessage string) error
+	ScheduleUpdateRetry(ctx context.Context, updateId string, workerId string, errorMessage string, nextAttemptAt time.Time) error
+	PostponeUpdate(ctx context.Context, updateId string, workerId string, nextAttemptAt time.Time) error
+	UpdateRate(ctx context.Context, updateId string, workerId string, from string, to string, quote model.RateQuote) error
+	ReclaimStuckUpdates(ctx context.Context, maxAttempts int, maxAge time.Duration) (model.ReclaimResult, error)
+	GetLastRate(ctx context.Context, from string, to string) (model.ExchangeRate, error)
+	GetLastRates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]model.ExchangeRate, error)
+	GetAllRates(ctx context.Context) ([]model.ExchangeRateDbo, error)
+	GetRateHistory(
+		ctx context.Context,
+		from string,
+		to string,
+		since *time.Time,
+		until *time.Time,
+		cursor *model.RateHistoryCursor,
+		limit int) (model.RateHistoryPage, error)
 }
 
 type PostgresExchangeRateRepository struct {
-	db            *sql.DB
-	rateStorage   storage.RateStorage
-	updateStorage storage.UpdateStorage
+	db             *sql.DB
+	rateStorage    storage.RateStorage
+	updateStorage  storage.UpdateStorage
+	historyStorage storage.HistoryStorage
 }
 
 func NewExchangeRateRepository(
 	db *sql.DB,
 	rateStorage storage.RateStorage,
-	rateUpdateStorage storage.UpdateStorage) *PostgresExchangeRateRepository {
+	rateUpdateStorage storage.UpdateStorage,
+	historyStorage storage.HistoryStorage) *PostgresExchangeRateRepository {
 	repository := PostgresExchangeRateRepository{
-		db:            db,
-		rateStorage:   rateStorage,
-		updateStorage: rateUpdateStorage,
+		db:             db,
+		rateStorage:    rateStorage,
+		updateStorage:  rateUpdateStorage,
+		historyStorage: historyStorage,
 	}
 	return &repository
 }
 
-func (r *PostgresExchangeRateRepository) GetOrCreateRateUpdate(from string, to string) (string, error) {
+func (r *PostgresExchangeRateRepository) GetOrCreateRateUpdate(ctx context.Context, from string, to string) (string, error) {
 	updateId := uuid.New()
-	update, err := r.updateStorage.GetOrCreateRateUpdate(updateId.String(), from, to)
+	update, err := r.updateStorage.GetOrCreateRateUpdate(ctx, updateId.String(), from, to)
 	if err != nil {
 		return "", err
 	}
 	return update.Id, nil
 }
 
-func (r *PostgresExchangeRateRepository) GetRateUpdate(updateId string) (model.ExchangeRate, error) {
-	update, err := r.updateStorage.GetRateUpdate(updateId)
+// GetOrCreateRateUpdates starts the updates of all pairs in one transaction
+func (r *PostgresExchangeRateRepository) GetOrCreateRateUpdates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return model.ExchangeRate{}, err
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	if update.Status != model.StatusDone {
-		return model.ExchangeRate{}, nil
+	updateIds := make(map[model.CurrencyPair]string, len(pairs))
+	for _, pair := range pairs {
+		update, err := r.updateStorage.GetOrCreateRateUpdateTx(ctx, tx, uuid.New().String(), pair.From, pair.To)
+		if err != nil {
+			return nil, err
+		}
+		updateIds[pair] = update.Id
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	rate := model.ExchangeRate{
-		Rate:           update.RateValue,
-		UpdateDateTime: update.UpdateTime,
+	return updateIds, nil
+}
+
+func (r *PostgresExchangeRateRepository) GetRateUpdate(ctx context.Context, updateId string) (model.RateUpdate, error) {
+	update, err := r.updateStorage.GetRateUpdate(ctx, updateId)
+	if err != nil {
+		return model.RateUpdate{}, err
 	}
 
-	return rate, nil
+	rateUpdate := model.RateUpdate{
+		Status:       update.Status,
+		CreatedAt:    update.CreatedAt,
+		Attempts:     update.Attempts,
+		ErrorMessage: update.ErrorMessage,
+	}
+
+	if update.Status == model.StatusDone {
+		rateUpdate.ExchangeRate = model.ExchangeRate{
+			Rate:           update.RateValue,
+			UpdateDateTime: update.UpdateTime,
+			Provider:       providerName(update.Provider),
+		}
+	}
+
+	return rateUpdate, nil
+}
+
+// GetRatesForUpdate claims up to fetchSize updates for the worker for the lease duration
+func (r *PostgresExchangeRateRepository) GetRatesForUpdate(ctx context.Context, workerId string, leaseDuration time.Duration, fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
+	return r.updateStorage.GetRatesForUpdate(ctx, workerId, leaseDuration, fetchSize)
+}
+
+func (r *PostgresExchangeRateRepository) SetUpdateError(ctx context.Context, updateId string, workerId string, errorMessage string) error {
+	return r.updateStorage.SetError(ctx, updateId, workerId, errorMessage)
 }
 
-func (r *PostgresExchangeRateRepository) GetRatesForUpdate(fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
-	return r.updateStorage.GetRatesForUpdate(fetchSize)
+func (r *PostgresExchangeRateRepository) ScheduleUpdateRetry(ctx context.Context, updateId string, workerId string, errorMessage string, nextAttemptAt time.Time) error {
+	return r.updateStorage.ScheduleRetry(ctx, updateId, workerId, errorMessage, nextAttemptAt)
 }
 
-func (r *PostgresExchangeRateRepository) SetUpdateError(updateId string) error {
-	return r.updateStorage.SetError(updateId)
+func (r *PostgresExchangeRateRepository) PostponeUpdate(ctx context.Context, updateId string, workerId string, nextAttemptAt time.Time) error {
+	return r.updateStorage.ReleaseClaim(ctx, updateId, workerId, nextAttemptAt)
 }
 
-func (r *PostgresExchangeRateRepository) UpdateRate(updateId string, from string, to string, rate decimal.Decimal) error {
+// UpdateRate stores the rate if the worker still holds the lease of the update. Returns internal.ErrLeaseLost otherwise
+func (r *PostgresExchangeRateRepository) UpdateRate(ctx context.Context, updateId string, workerId string, from string, to string, quote model.RateQuote) error {
 
-	tx, err := r.db.BeginTx(context.Background(), nil)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	updateTime := time.Now().UTC()
+	rate, provider := quote.Rate, quote.Provider
 
 	updateRateDbo := model.ExchangeRateUpdateDbo{
 		Id:           updateId,
@@ -91,6 +148,10 @@ func (r *PostgresExchangeRateRepository) UpdateRate(updateId string, from string
 		Status:       model.StatusDone,
 		UpdateTime:   &updateTime,
 		RateValue:    &rate,
+		ClaimedBy:    workerId,
+		Provider:     &provider,
+		Sources:      quote.Sources,
+		Spread:       quote.Spread,
 	}
 
 	rateDbo := model.ExchangeRateDbo{
@@ -98,21 +159,61 @@ func (r *PostgresExchangeRateRepository) UpdateRate(updateId string, from string
 		ToCurrency:   to,
 		RateValue:    &rate,
 		UpdateTime:   &updateTime,
+		Provider:     &provider,
 	}
 
-	if err := r.updateStorage.UpdateRateTx(tx, &updateRateDbo); err != nil {
+	if err := r.updateStorage.UpdateRateTx(ctx, tx, &updateRateDbo); err != nil {
 		return err
 	}
 
-	if err := r.rateStorage.SetRateTx(tx, &rateDbo); err != nil {
+	historyDbo := model.ExchangeRateHistoryDbo{
+		UpdateId:     updateId,
+		FromCurrency: from,
+		ToCurrency:   to,
+		RateValue:    &rate,
+		UpdateTime:   &updateTime,
+		Provider:     &provider,
+	}
+
+	if err := r.rateStorage.SetRateTx(ctx, tx, &rateDbo); err != nil {
+		return err
+	}
+
+	if err := r.historyStorage.AddRateTx(ctx, tx, &historyDbo); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
 
-func (r *PostgresExchangeRateRepository) GetLastRate(from string, to string) (model.ExchangeRate, error) {
-	rate, err := r.rateStorage.GetRate(from, to)
+// ReclaimStuckUpdates fails updates older than maxAge or out of attempts and requeues the other updates
+// whose lease expired, so they are picked by another worker
+func (r *PostgresExchangeRateRepository) ReclaimStuckUpdates(ctx context.Context, maxAttempts int, maxAge time.Duration) (model.ReclaimResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.ReclaimResult{}, err
+	}
+	defer tx.Rollback()
+
+	failed, err := r.updateStorage.FailStuckUpdatesTx(ctx, tx, maxAttempts, maxAge)
+	if err != nil {
+		return model.ReclaimResult{}, err
+	}
+
+	requeued, err := r.updateStorage.RequeueExpiredLeasesTx(ctx, tx)
+	if err != nil {
+		return model.ReclaimResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.ReclaimResult{}, err
+	}
+
+	return model.ReclaimResult{Requeued: requeued, Failed: failed}, nil
+}
+
+func (r *PostgresExchangeRateRepository) GetLastRate(ctx context.Context, from string, to string) (model.ExchangeRate, error) {
+	rate, err := r.rateStorage.GetRate(ctx, from, to)
 
 	if err != nil {
 		return model.ExchangeRate{}, err
@@ -125,7 +226,76 @@ func (r *PostgresExchangeRateRepository) GetLastRate(from string, to string) (mo
 	resultRate := model.ExchangeRate{
 		Rate:           rate.RateValue,
 		UpdateDateTime: rate.UpdateTime,
+		Provider:       providerName(rate.Provider),
 	}
 
 	return resultRate, nil
 }
+
+// GetLastRates returns stored rates of the pairs. Pairs without a stored rate are not in the result
+func (r *PostgresExchangeRateRepository) GetLastRates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]model.ExchangeRate, error) {
+	dbos, err := r.rateStorage.GetRates(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[model.CurrencyPair]model.ExchangeRate, len(dbos))
+	for _, dbo := range dbos {
+		pair := model.CurrencyPair{From: dbo.FromCurrency, To: dbo.ToCurrency}
+		rates[pair] = model.ExchangeRate{
+			Rate:           dbo.RateValue,
+			UpdateDateTime: dbo.UpdateTime,
+			Provider:       providerName(dbo.Provider),
+		}
+	}
+
+	return rates, nil
+}
+
+func (r *PostgresExchangeRateRepository) GetAllRates(ctx context.Context) ([]model.ExchangeRateDbo, error) {
+	return r.rateStorage.GetAllRates(ctx)
+}
+
+func (r *PostgresExchangeRateRepository) GetRateHistory(
+	ctx context.Context,
+	from string,
+	to string,
+	since *time.Time,
+	until *time.Time,
+	cursor *model.RateHistoryCursor,
+	limit int) (model.RateHistoryPage, error) {
+	// Fetch one extra row to find out whether there is a next page
+	dbos, err := r.historyStorage.GetRateHistory(ctx, from, to, since, until, cursor, limit+1)
+	if err != nil {
+		return model.RateHistoryPage{}, err
+	}
+
+	page := model.RateHistoryPage{}
+	if len(dbos) > limit {
+		dbos = dbos[:limit]
+		last := dbos[limit-1]
+		page.NextCursor = &model.RateHistoryCursor{
+			UpdateTime: *last.UpdateTime,
+			Id:         last.Id,
+		}
+	}
+
+	page.Rates = make([]model.ExchangeRate, 0, len(dbos))
+	for _, dbo := range dbos {
+		page.Rates = append(page.Rates, model.ExchangeRate{
+			Rate:           dbo.RateValue,
+			UpdateDateTime: dbo.UpdateTime,
+			Provider:       providerName(dbo.Provider),
+		})
+	}
+
+	return page, nil
+}
+
+// providerName returns empty name for rates stored before the provider was recorded
+func providerName(provider *string) string {
+	if provider == nil {
+		return ""
+	}
+	return *provider
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/model"
 	"testing"
 	"time"
@@ -18,7 +20,7 @@ type MockExchangeRateStorage struct {
 	mock.Mock
 }
 
-func (m *MockExchangeRateStorage) GetRate(from string, to string) (*model.ExchangeRateDbo, error) {
+func (m *MockExchangeRateStorage) GetRate(ctx context.Context, from string, to string) (*model.ExchangeRateDbo, error) {
 	args := m.Called(from, to)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -26,7 +28,17 @@ func (m *MockExchangeRateStorage) GetRate(from string, to string) (*model.Exchan
 	return args.Get(0).(*model.ExchangeRateDbo), args.Error(1)
 }
 
-func (m *MockExchangeRateStorage) SetRateTx(tx *sql.Tx, rateDbo *model.ExchangeRateDbo) error {
+func (m *MockExchangeRateStorage) GetRates(ctx context.Context, pairs []model.CurrencyPair) ([]model.ExchangeRateDbo, error) {
+	args := m.Called(pairs)
+	return args.Get(0).([]model.ExchangeRateDbo), args.Error(1)
+}
+
+func (m *MockExchangeRateStorage) GetAllRates(ctx context.Context) ([]model.ExchangeRateDbo, error) {
+	args := m.Called()
+	return args.Get(0).([]model.ExchangeRateDbo), args.Error(1)
+}
+
+func (m *MockExchangeRateStorage) SetRateTx(ctx context.Context, tx *sql.Tx, rateDbo *model.ExchangeRateDbo) error {
 	args := m.Called(tx, rateDbo)
 	return args.Error(0)
 }
@@ -35,33 +47,82 @@ type MockExchangeRateUpdateStorage struct {
 	mock.Mock
 }
 
-func (m *MockExchangeRateUpdateStorage) GetOrCreateRateUpdate(updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
+func (m *MockExchangeRateUpdateStorage) GetOrCreateRateUpdate(ctx context.Context, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
 	args := m.Called(updateId, from, to)
 	return args.Get(0).(*model.ExchangeRateUpdateDbo), args.Error(1)
 }
 
-func (m *MockExchangeRateUpdateStorage) GetRateUpdate(updateId string) (*model.ExchangeRateUpdateDbo, error) {
+func (m *MockExchangeRateUpdateStorage) GetOrCreateRateUpdateTx(ctx context.Context, tx *sql.Tx, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
+	args := m.Called(tx, updateId, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ExchangeRateUpdateDbo), args.Error(1)
+}
+
+func (m *MockExchangeRateUpdateStorage) GetRateUpdate(ctx context.Context, updateId string) (*model.ExchangeRateUpdateDbo, error) {
 	args := m.Called(updateId)
 	return args.Get(0).(*model.ExchangeRateUpdateDbo), args.Error(1)
 }
 
-func (m *MockExchangeRateUpdateStorage) GetRatesForUpdate(fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
-	args := m.Called(fetchSize)
+func (m *MockExchangeRateUpdateStorage) GetRatesForUpdate(ctx context.Context, workerId string, leaseDuration time.Duration, fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
+	args := m.Called(workerId, leaseDuration, fetchSize)
 	return args.Get(0).([]model.ExchangeRateUpdateDbo), args.Error(1)
 }
 
-func (m *MockExchangeRateUpdateStorage) UpdateRateTx(tx *sql.Tx, updateDbo *model.ExchangeRateUpdateDbo) error {
+func (m *MockExchangeRateUpdateStorage) UpdateRateTx(ctx context.Context, tx *sql.Tx, updateDbo *model.ExchangeRateUpdateDbo) error {
 	args := m.Called(tx, updateDbo)
 	return args.Error(0)
 }
 
-func (m *MockExchangeRateUpdateStorage) SetError(updateId string) error {
-	args := m.Called(updateId)
+func (m *MockExchangeRateUpdateStorage) SetError(ctx context.Context, updateId string, workerId string, errorMessage string) error {
+	args := m.Called(updateId, workerId, errorMessage)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateUpdateStorage) ScheduleRetry(ctx context.Context, updateId string, workerId string, errorMessage string, nextAttemptAt time.Time) error {
+	args := m.Called(updateId, workerId, errorMessage, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateUpdateStorage) ReleaseClaim(ctx context.Context, updateId string, workerId string, nextAttemptAt time.Time) error {
+	args := m.Called(updateId, workerId, nextAttemptAt)
 	return args.Error(0)
 }
 
+func (m *MockExchangeRateUpdateStorage) FailStuckUpdatesTx(ctx context.Context, tx *sql.Tx, maxAttempts int, maxAge time.Duration) (int, error) {
+	args := m.Called(tx, maxAttempts, maxAge)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockExchangeRateUpdateStorage) RequeueExpiredLeasesTx(ctx context.Context, tx *sql.Tx) (int, error) {
+	args := m.Called(tx)
+	return args.Int(0), args.Error(1)
+}
+
+type MockExchangeRateHistoryStorage struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateHistoryStorage) AddRateTx(ctx context.Context, tx *sql.Tx, historyDbo *model.ExchangeRateHistoryDbo) error {
+	args := m.Called(tx, historyDbo)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateHistoryStorage) GetRateHistory(
+	ctx context.Context,
+	from string,
+	to string,
+	since *time.Time,
+	until *time.Time,
+	after *model.RateHistoryCursor,
+	limit int) ([]model.ExchangeRateHistoryDbo, error) {
+	args := m.Called(from, to, since, until, after, limit)
+	return args.Get(0).([]model.ExchangeRateHistoryDbo), args.Error(1)
+}
+
 func TestGetOrCreateRateUpdate_ShouldReturnUpdateIdFromStorage(t *testing.T) {
-	_, mockUpdateStorage, repo, _, _ := createMocks(t)
+	_, mockUpdateStorage, _, repo, _, _ := createMocks(t)
 
 	expectedUpdateId := "update-id-123"
 	update := &model.ExchangeRateUpdateDbo{
@@ -74,15 +135,55 @@ func TestGetOrCreateRateUpdate_ShouldReturnUpdateIdFromStorage(t *testing.T) {
 	mockUpdateStorage.On("GetOrCreateRateUpdate", mock.AnythingOfType("string"), update.FromCurrency, update.ToCurrency).
 		Return(update, nil)
 
-	updateId, err := repo.GetOrCreateRateUpdate(update.FromCurrency, update.ToCurrency)
+	updateId, err := repo.GetOrCreateRateUpdate(context.Background(), update.FromCurrency, update.ToCurrency)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUpdateId, updateId)
 	mockUpdateStorage.AssertExpectations(t)
 }
 
+func TestGetOrCreateRateUpdates_ShouldCreateAllInOneTransaction(t *testing.T) {
+	_, mockUpdateStorage, _, repo, _, sqlMock := createMocks(t)
+
+	first := model.CurrencyPair{From: "USD", To: "EUR"}
+	second := model.CurrencyPair{From: "USD", To: "MXN"}
+
+	// The transaction is matched, not printed: its fields are written by database/sql after the commit
+	anyTx := mock.MatchedBy(func(*sql.Tx) bool { return true })
+	sqlMock.ExpectBegin()
+	mockUpdateStorage.On("GetOrCreateRateUpdateTx", anyTx, mock.AnythingOfType("string"), first.From, first.To).
+		Return(&model.ExchangeRateUpdateDbo{Id: "update-1"}, nil)
+	mockUpdateStorage.On("GetOrCreateRateUpdateTx", anyTx, mock.AnythingOfType("string"), second.From, second.To).
+		Return(&model.ExchangeRateUpdateDbo{Id: "update-2"}, nil)
+	sqlMock.ExpectCommit()
+
+	updateIds, err := repo.GetOrCreateRateUpdates(context.Background(), []model.CurrencyPair{first, second})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[model.CurrencyPair]string{first: "update-1", second: "update-2"}, updateIds)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockUpdateStorage.AssertNumberOfCalls(t, "GetOrCreateRateUpdateTx", 2)
+}
+
+func TestGetOrCreateRateUpdates_ShouldRollbackWhenError(t *testing.T) {
+	_, mockUpdateStorage, _, repo, _, sqlMock := createMocks(t)
+
+	expectedError := errors.New("error")
+
+	sqlMock.ExpectBegin()
+	mockUpdateStorage.On("GetOrCreateRateUpdateTx", mock.AnythingOfType("*sql.Tx"), mock.AnythingOfType("string"), "USD", "EUR").
+		Return(nil, expectedError)
+	sqlMock.ExpectRollback()
+
+	updateIds, err := repo.GetOrCreateRateUpdates(context.Background(), []model.CurrencyPair{{From: "USD", To: "EUR"}})
+
+	assert.Nil(t, updateIds)
+	assert.Equal(t, expectedError, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
 func TestGetRateUpdate_Success(t *testing.T) {
-	_, mockUpdateStorage, repo, _, _ := createMocks(t)
+	_, mockUpdateStorage, _, repo, _, _ := createMocks(t)
 
 	updateId := "update-123"
 	updateTime := time.Now().UTC()
@@ -99,7 +200,7 @@ func TestGetRateUpdate_Success(t *testing.T) {
 
 	mockUpdateStorage.On("GetRateUpdate", updateId).Return(updateDbo, nil)
 
-	result, err := repo.GetRateUpdate(updateId)
+	result, err := repo.GetRateUpdate(context.Background(), updateId)
 
 	assert.NoError(t, err)
 	assert.Equal(t, &rate, result.Rate)
@@ -108,7 +209,7 @@ func TestGetRateUpdate_Success(t *testing.T) {
 }
 
 func TestGetRateUpdate_ReturnsEmptyWhenStatusNotDone(t *testing.T) {
-	_, mockUpdateStorage, repo, _, _ := createMocks(t)
+	_, mockUpdateStorage, _, repo, _, _ := createMocks(t)
 
 	updateId := "update-123"
 	updateDbo := &model.ExchangeRateUpdateDbo{
@@ -120,16 +221,46 @@ func TestGetRateUpdate_ReturnsEmptyWhenStatusNotDone(t *testing.T) {
 
 	mockUpdateStorage.On("GetRateUpdate", updateId).Return(updateDbo, nil)
 
-	result, err := repo.GetRateUpdate(updateId)
+	result, err := repo.GetRateUpdate(context.Background(), updateId)
 
 	assert.NoError(t, err)
 	assert.Nil(t, result.Rate)
 	assert.Nil(t, result.UpdateDateTime)
+	assert.Equal(t, model.StatusUpdating, result.Status)
+	mockUpdateStorage.AssertExpectations(t)
+}
+
+func TestGetRateUpdate_ShouldReturnErrorDetails(t *testing.T) {
+	_, mockUpdateStorage, _, repo, _, _ := createMocks(t)
+
+	updateId := "update-123"
+	createdAt := time.Now().UTC()
+	errorMessage := "api error"
+	updateDbo := &model.ExchangeRateUpdateDbo{
+		Id:           updateId,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Status:       model.StatusError,
+		CreatedAt:    &createdAt,
+		Attempts:     3,
+		ErrorMessage: &errorMessage,
+	}
+
+	mockUpdateStorage.On("GetRateUpdate", updateId).Return(updateDbo, nil)
+
+	result, err := repo.GetRateUpdate(context.Background(), updateId)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.Rate)
+	assert.Equal(t, model.StatusError, result.Status)
+	assert.Equal(t, &createdAt, result.CreatedAt)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, &errorMessage, result.ErrorMessage)
 	mockUpdateStorage.AssertExpectations(t)
 }
 
 func TestUpdateRate_Success(t *testing.T) {
-	mockRateStorage, mockUpdateStorage, repo, _, sqlMock := createMocks(t)
+	mockRateStorage, mockUpdateStorage, mockHistoryStorage, repo, _, sqlMock := createMocks(t)
 
 	rate := decimal.NewFromFloat(1.35)
 	updateId := "update-123"
@@ -143,27 +274,64 @@ func TestUpdateRate_Success(t *testing.T) {
 			dbo.FromCurrency == fromCurrency &&
 			dbo.ToCurrency == toCurrency &&
 			dbo.Status == model.StatusDone &&
+			dbo.ClaimedBy == "worker-1" &&
+			*dbo.Provider == "currency-api" &&
 			dbo.RateValue.Equal(rate)
 	})).Return(nil)
 
 	mockRateStorage.On("SetRateTx", mock.AnythingOfType("*sql.Tx"), mock.MatchedBy(func(dbo *model.ExchangeRateDbo) bool {
 		return dbo.FromCurrency == fromCurrency &&
+			dbo.ToCurrency == toCurrency &&
+			*dbo.Provider == "currency-api" &&
+			dbo.RateValue.Equal(rate)
+	})).Return(nil)
+
+	mockHistoryStorage.On("AddRateTx", mock.AnythingOfType("*sql.Tx"), mock.MatchedBy(func(dbo *model.ExchangeRateHistoryDbo) bool {
+		return dbo.UpdateId == updateId &&
+			dbo.FromCurrency == fromCurrency &&
 			dbo.ToCurrency == toCurrency &&
 			dbo.RateValue.Equal(rate)
 	})).Return(nil)
 
 	sqlMock.ExpectCommit()
 
-	err := repo.UpdateRate(updateId, fromCurrency, toCurrency, rate)
+	err := repo.UpdateRate(context.Background(), updateId, "worker-1", fromCurrency, toCurrency, model.RateQuote{Rate: rate, Provider: "currency-api"})
 
 	assert.NoError(t, err)
 	assert.NoError(t, sqlMock.ExpectationsWereMet())
 	mockUpdateStorage.AssertExpectations(t)
 	mockRateStorage.AssertExpectations(t)
+	mockHistoryStorage.AssertExpectations(t)
+}
+
+func TestUpdateRate_ShouldRollbackWhenAddHistoryError(t *testing.T) {
+	mockRateStorage, mockUpdateStorage, mockHistoryStorage, repo, _, sqlMock := createMocks(t)
+
+	rate := decimal.NewFromFloat(1.35)
+	expectedError := errors.New("add history error")
+
+	sqlMock.ExpectBegin()
+
+	mockUpdateStorage.On("UpdateRateTx", mock.AnythingOfType("*sql.Tx"), mock.Anything).
+		Return(nil)
+
+	mockRateStorage.On("SetRateTx", mock.AnythingOfType("*sql.Tx"), mock.Anything).
+		Return(nil)
+
+	mockHistoryStorage.On("AddRateTx", mock.AnythingOfType("*sql.Tx"), mock.Anything).
+		Return(expectedError)
+
+	sqlMock.ExpectRollback()
+
+	err := repo.UpdateRate(context.Background(), "update-123", "worker-1", "USD", "EUR", model.RateQuote{Rate: rate, Provider: "currency-api"})
+
+	assert.Equal(t, expectedError, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockHistoryStorage.AssertExpectations(t)
 }
 
 func TestUpdateRate_ShouldRollbackWhenError(t *testing.T) {
-	_, mockUpdateStorage, repo, _, sqlMock := createMocks(t)
+	_, mockUpdateStorage, _, repo, _, sqlMock := createMocks(t)
 
 	rate := decimal.NewFromFloat(1.35)
 	expectedError := errors.New("error")
@@ -175,7 +343,7 @@ func TestUpdateRate_ShouldRollbackWhenError(t *testing.T) {
 
 	sqlMock.ExpectRollback()
 
-	err := repo.UpdateRate("update-123", "USD", "EUR", rate)
+	err := repo.UpdateRate(context.Background(), "update-123", "worker-1", "USD", "EUR", model.RateQuote{Rate: rate, Provider: "currency-api"})
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -184,7 +352,7 @@ func TestUpdateRate_ShouldRollbackWhenError(t *testing.T) {
 }
 
 func TestUpdateRate_ShouldRollbackWhenSetRateError(t *testing.T) {
-	mockRateStorage, mockUpdateStorage, repo, _, sqlMock := createMocks(t)
+	mockRateStorage, mockUpdateStorage, _, repo, _, sqlMock := createMocks(t)
 
 	rate := decimal.NewFromFloat(1.35)
 	expectedError := errors.New("set rate error")
@@ -199,7 +367,7 @@ func TestUpdateRate_ShouldRollbackWhenSetRateError(t *testing.T) {
 
 	sqlMock.ExpectRollback()
 
-	err := repo.UpdateRate("update-123", "USD", "EUR", rate)
+	err := repo.UpdateRate(context.Background(), "update-123", "worker-1", "USD", "EUR", model.RateQuote{Rate: rate, Provider: "currency-api"})
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -208,8 +376,61 @@ func TestUpdateRate_ShouldRollbackWhenSetRateError(t *testing.T) {
 	mockRateStorage.AssertExpectations(t)
 }
 
+func TestUpdateRate_ShouldRollbackWhenLeaseLost(t *testing.T) {
+	mockRateStorage, mockUpdateStorage, mockHistoryStorage, repo, _, sqlMock := createMocks(t)
+
+	rate := decimal.NewFromFloat(1.35)
+
+	sqlMock.ExpectBegin()
+
+	mockUpdateStorage.On("UpdateRateTx", mock.AnythingOfType("*sql.Tx"), mock.Anything).
+		Return(internal.ErrLeaseLost)
+
+	sqlMock.ExpectRollback()
+
+	err := repo.UpdateRate(context.Background(), "update-123", "worker-1", "USD", "EUR", model.RateQuote{Rate: rate, Provider: "currency-api"})
+
+	assert.ErrorIs(t, err, internal.ErrLeaseLost)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockRateStorage.AssertNotCalled(t, "SetRateTx", mock.Anything, mock.Anything)
+	mockHistoryStorage.AssertNotCalled(t, "AddRateTx", mock.Anything, mock.Anything)
+}
+
+func TestReclaimStuckUpdates_Success(t *testing.T) {
+	_, mockUpdateStorage, _, repo, _, sqlMock := createMocks(t)
+
+	sqlMock.ExpectBegin()
+	mockUpdateStorage.On("FailStuckUpdatesTx", mock.AnythingOfType("*sql.Tx"), 5, time.Hour).Return(1, nil)
+	mockUpdateStorage.On("RequeueExpiredLeasesTx", mock.AnythingOfType("*sql.Tx")).Return(2, nil)
+	sqlMock.ExpectCommit()
+
+	result, err := repo.ReclaimStuckUpdates(context.Background(), 5, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReclaimResult{Requeued: 2, Failed: 1}, result)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockUpdateStorage.AssertExpectations(t)
+}
+
+func TestReclaimStuckUpdates_ShouldRollbackWhenError(t *testing.T) {
+	_, mockUpdateStorage, _, repo, _, sqlMock := createMocks(t)
+
+	expectedError := errors.New("error")
+
+	sqlMock.ExpectBegin()
+	mockUpdateStorage.On("FailStuckUpdatesTx", mock.AnythingOfType("*sql.Tx"), 5, time.Hour).Return(1, nil)
+	mockUpdateStorage.On("RequeueExpiredLeasesTx", mock.AnythingOfType("*sql.Tx")).Return(0, expectedError)
+	sqlMock.ExpectRollback()
+
+	_, err := repo.ReclaimStuckUpdates(context.Background(), 5, time.Hour)
+
+	assert.Equal(t, expectedError, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockUpdateStorage.AssertExpectations(t)
+}
+
 func TestGetLastRate_Success(t *testing.T) {
-	mockRateStorage, _, repo, _, _ := createMocks(t)
+	mockRateStorage, _, _, repo, _, _ := createMocks(t)
 
 	fromCurrency := "USD"
 	toCurrency := "EUR"
@@ -225,7 +446,7 @@ func TestGetLastRate_Success(t *testing.T) {
 
 	mockRateStorage.On("GetRate", fromCurrency, toCurrency).Return(rateDbo, nil)
 
-	result, err := repo.GetLastRate(fromCurrency, toCurrency)
+	result, err := repo.GetLastRate(context.Background(), fromCurrency, toCurrency)
 
 	assert.NoError(t, err)
 	assert.Equal(t, &rateValue, result.Rate)
@@ -234,13 +455,13 @@ func TestGetLastRate_Success(t *testing.T) {
 }
 
 func TestGetLastRate_ReturnsEmptyWhenRateNotFound(t *testing.T) {
-	mockRateStorage, _, repo, _, _ := createMocks(t)
+	mockRateStorage, _, _, repo, _, _ := createMocks(t)
 
 	from := "USD"
 	to := "EUR"
 	mockRateStorage.On("GetRate", from, to).Return(nil, nil)
 
-	result, err := repo.GetLastRate(from, to)
+	result, err := repo.GetLastRate(context.Background(), from, to)
 
 	assert.NoError(t, err)
 	assert.Nil(t, result.Rate)
@@ -248,18 +469,90 @@ func TestGetLastRate_ReturnsEmptyWhenRateNotFound(t *testing.T) {
 	mockRateStorage.AssertExpectations(t)
 }
 
+func TestGetRateHistory_ShouldReturnNextCursorWhenMoreRowsExist(t *testing.T) {
+	_, _, mockHistoryStorage, repo, _, _ := createMocks(t)
+
+	from, to := "USD", "EUR"
+	firstTime, secondTime := time.Now().UTC(), time.Now().UTC().Add(time.Minute)
+	firstRate, secondRate := decimal.NewFromFloat(1.1), decimal.NewFromFloat(1.2)
+
+	dbos := []model.ExchangeRateHistoryDbo{
+		{Id: 1, FromCurrency: from, ToCurrency: to, RateValue: &firstRate, UpdateTime: &firstTime},
+		{Id: 2, FromCurrency: from, ToCurrency: to, RateValue: &secondRate, UpdateTime: &secondTime},
+	}
+
+	mockHistoryStorage.On("GetRateHistory", from, to, (*time.Time)(nil), (*time.Time)(nil), (*model.RateHistoryCursor)(nil), 2).
+		Return(dbos, nil)
+
+	page, err := repo.GetRateHistory(context.Background(), from, to, nil, nil, nil, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Rates, 1)
+	assert.Equal(t, &firstRate, page.Rates[0].Rate)
+	assert.Equal(t, &firstTime, page.Rates[0].UpdateDateTime)
+	assert.Equal(t, &model.RateHistoryCursor{UpdateTime: firstTime, Id: 1}, page.NextCursor)
+	mockHistoryStorage.AssertExpectations(t)
+}
+
+func TestGetRateHistory_ShouldNotReturnNextCursorOnLastPage(t *testing.T) {
+	_, _, mockHistoryStorage, repo, _, _ := createMocks(t)
+
+	from, to := "USD", "EUR"
+	updateTime := time.Now().UTC()
+	rate := decimal.NewFromFloat(1.1)
+	cursor := &model.RateHistoryCursor{UpdateTime: updateTime.Add(-time.Minute), Id: 5}
+
+	dbos := []model.ExchangeRateHistoryDbo{
+		{Id: 6, FromCurrency: from, ToCurrency: to, RateValue: &rate, UpdateTime: &updateTime},
+	}
+
+	mockHistoryStorage.On("GetRateHistory", from, to, (*time.Time)(nil), (*time.Time)(nil), cursor, 11).
+		Return(dbos, nil)
+
+	page, err := repo.GetRateHistory(context.Background(), from, to, nil, nil, cursor, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Rates, 1)
+	assert.Nil(t, page.NextCursor)
+	mockHistoryStorage.AssertExpectations(t)
+}
+
+func TestGetLastRates_ShouldReturnRatesByPair(t *testing.T) {
+	mockRateStorage, _, _, repo, _, _ := createMocks(t)
+
+	found := model.CurrencyPair{From: "USD", To: "EUR"}
+	missing := model.CurrencyPair{From: "USD", To: "MXN"}
+	pairs := []model.CurrencyPair{found, missing}
+	updateTime := time.Now().UTC()
+	rateValue := decimal.NewFromFloat(0.9)
+
+	mockRateStorage.On("GetRates", pairs).Return([]model.ExchangeRateDbo{
+		{FromCurrency: found.From, ToCurrency: found.To, RateValue: &rateValue, UpdateTime: &updateTime},
+	}, nil)
+
+	rates, err := repo.GetLastRates(context.Background(), pairs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[model.CurrencyPair]model.ExchangeRate{
+		found: {Rate: &rateValue, UpdateDateTime: &updateTime},
+	}, rates)
+	mockRateStorage.AssertExpectations(t)
+}
+
 func createMocks(t *testing.T) (
 	*MockExchangeRateStorage,
 	*MockExchangeRateUpdateStorage,
+	*MockExchangeRateHistoryStorage,
 	*PostgresExchangeRateRepository,
 	*sql.DB,
 	sqlmock.Sqlmock) {
 	mockUpdateStorage := new(MockExchangeRateUpdateStorage)
 	mockRateStorage := new(MockExchangeRateStorage)
+	mockHistoryStorage := new(MockExchangeRateHistoryStorage)
 
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 
-	repo := NewExchangeRateRepository(db, mockRateStorage, mockUpdateStorage)
-	return mockRateStorage, mockUpdateStorage, repo, db, mock
+	repo := NewExchangeRateRepository(db, mockRateStorage, mockUpdateStorage, mockHistoryStorage)
+	return mockRateStorage, mockUpdateStorage, mockHistoryStorage, repo, db, mock
 }
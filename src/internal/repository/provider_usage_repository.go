@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"exchange-rates-service/src/internal/storage"
+	"time"
+)
+
+// PostgresProviderUsageRepository is the integration.QuotaCounter of the rate limited provider clients
+type PostgresProviderUsageRepository struct {
+	usageStorage storage.ProviderUsageStorage
+}
+
+func NewProviderUsageRepository(usageStorage storage.ProviderUsageStorage) *PostgresProviderUsageRepository {
+	return &PostgresProviderUsageRepository{usageStorage: usageStorage}
+}
+
+func (r *PostgresProviderUsageRepository) ReserveProviderRequest(ctx context.Context, provider string, period time.Time, maxRequests int) (int, bool, error) {
+	return r.usageStorage.ReserveRequest(ctx, provider, period, maxRequests)
+}
+
+func (r *PostgresProviderUsageRepository) GetProviderUsage(ctx context.Context, provider string, period time.Time) (int, error) {
+	return r.usageStorage.GetUsage(ctx, provider, period)
+}
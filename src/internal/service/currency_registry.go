@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal"
+	"exchange-rates-service/src/internal/model"
+	"exchange-rates-service/src/internal/repository"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CurrencyRegistry caches currencies stored in the database. The cache is reloaded after the refresh interval,
+// so currencies enabled through another service instance become available without a restart
+type CurrencyRegistry struct {
+	config     *config.Config
+	repository repository.CurrencyRepository
+	mutex      sync.RWMutex
+	currencies map[string]model.Currency
+	loadedAt   time.Time
+}
+
+func NewCurrencyRegistry(config *config.Config, repo repository.CurrencyRepository) *CurrencyRegistry {
+	return &CurrencyRegistry{
+		config:     config,
+		repository: repo,
+	}
+}
+
+// GetActive returns the currency if it is known and active, BadRequest error otherwise
+func (r *CurrencyRegistry) GetActive(ctx context.Context, code string) (model.Currency, error) {
+	currencies, err := r.load(ctx)
+	if err != nil {
+		return model.Currency{}, err
+	}
+
+	currency, ok := currencies[code]
+	if !ok || !currency.Active {
+		return model.Currency{}, internal.NewBadRequestError(fmt.Sprintf("currency %s not supported", code))
+	}
+
+	return currency, nil
+}
+
+// GetCurrencies returns all known currencies ordered by code
+func (r *CurrencyRegistry) GetCurrencies(ctx context.Context) ([]model.Currency, error) {
+	currencies, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := slices.Collect(maps.Values(currencies))
+	slices.SortFunc(result, func(a, b model.Currency) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return result, nil
+}
+
+func (r *CurrencyRegistry) SetActive(ctx context.Context, code string, active bool) (model.Currency, error) {
+	if code == "" {
+		return model.Currency{}, internal.NewBadRequestError("currency code is not set")
+	}
+
+	currency, err := r.repository.SetCurrencyActive(ctx, code, active)
+	if err != nil {
+		return model.Currency{}, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.currencies != nil {
+		// Readers may hold the current map, so it is replaced instead of being modified
+		currencies := maps.Clone(r.currencies)
+		currencies[code] = currency
+		r.currencies = currencies
+	}
+
+	return currency, nil
+}
+
+func (r *CurrencyRegistry) load(ctx context.Context) (map[string]model.Currency, error) {
+	r.mutex.RLock()
+	currencies, loadedAt := r.currencies, r.loadedAt
+	r.mutex.RUnlock()
+
+	if currencies != nil && time.Since(loadedAt) < r.config.CurrencyRefreshInterval {
+		return currencies, nil
+	}
+
+	stored, err := r.repository.GetCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies = make(map[string]model.Currency, len(stored))
+	for _, currency := range stored {
+		currencies[currency.Code] = currency
+	}
+
+	r.mutex.Lock()
+	r.currencies = currencies
+	r.loadedAt = time.Now()
+	r.mutex.Unlock()
+
+	return currencies, nil
+}
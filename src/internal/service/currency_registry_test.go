@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal"
+	"exchange-rates-service/src/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCurrencyRepository struct {
+	mock.Mock
+}
+
+func (m *mockCurrencyRepository) GetCurrencies(ctx context.Context) ([]model.Currency, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Currency), args.Error(1)
+}
+
+func (m *mockCurrencyRepository) SetCurrencyActive(ctx context.Context, code string, active bool) (model.Currency, error) {
+	args := m.Called(code, active)
+	return args.Get(0).(model.Currency), args.Error(1)
+}
+
+var testCurrencies = []model.Currency{
+	{Code: "EUR", NumericCode: "978", MinorUnits: 2, Name: "Euro", Active: true},
+	{Code: "GBP", NumericCode: "826", MinorUnits: 2, Name: "Pound Sterling", Active: false},
+	{Code: "MXN", NumericCode: "484", MinorUnits: 2, Name: "Mexican Peso", Active: true},
+	{Code: "USD", NumericCode: "840", MinorUnits: 2, Name: "US Dollar", Active: true},
+}
+
+func TestGetActive_ReturnsActiveCurrency(t *testing.T) {
+	mockRepo, registry := createMockRegistry()
+	mockRepo.On("GetCurrencies").Return(testCurrencies, nil).Once()
+
+	currency, err := registry.GetActive(context.Background(), "EUR")
+
+	assert.NoError(t, err)
+	assert.Equal(t, testCurrencies[0], currency)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetActive_ThrowsErrorWhenCurrencyInactiveOrUnknown(t *testing.T) {
+	mockRepo, registry := createMockRegistry()
+	mockRepo.On("GetCurrencies").Return(testCurrencies, nil).Once()
+
+	_, err := registry.GetActive(context.Background(), "GBP")
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+
+	_, err = registry.GetActive(context.Background(), "UNKNOWN")
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+
+	mockRepo.AssertNumberOfCalls(t, "GetCurrencies", 1)
+}
+
+func TestGetActive_ReturnsLoadError(t *testing.T) {
+	mockRepo, registry := createMockRegistry()
+	expectedError := errors.New("database error")
+	mockRepo.On("GetCurrencies").Return([]model.Currency(nil), expectedError)
+
+	_, err := registry.GetActive(context.Background(), "EUR")
+
+	assert.Equal(t, expectedError, err)
+}
+
+func TestSetActive_ShouldUpdateCachedCurrency(t *testing.T) {
+	mockRepo, registry := createMockRegistry()
+	mockRepo.On("GetCurrencies").Return(testCurrencies, nil).Once()
+
+	enabled := testCurrencies[1]
+	enabled.Active = true
+	mockRepo.On("SetCurrencyActive", "GBP", true).Return(enabled, nil)
+
+	_, err := registry.GetActive(context.Background(), "EUR")
+	assert.NoError(t, err)
+
+	currency, err := registry.SetActive(context.Background(), "GBP", true)
+	assert.NoError(t, err)
+	assert.Equal(t, enabled, currency)
+
+	currency, err = registry.GetActive(context.Background(), "GBP")
+	assert.NoError(t, err)
+	assert.True(t, currency.Active)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetCurrencies_ShouldReturnSortedCurrencies(t *testing.T) {
+	mockRepo, registry := createMockRegistry()
+	mockRepo.On("GetCurrencies").Return([]model.Currency{testCurrencies[3], testCurrencies[0]}, nil)
+
+	currencies, err := registry.GetCurrencies(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Currency{testCurrencies[0], testCurrencies[3]}, currencies)
+}
+
+func createMockRegistry() (*mockCurrencyRepository, *CurrencyRegistry) {
+	mockRepo := new(mockCurrencyRepository)
+	config := &config.Config{CurrencyRefreshInterval: time.Minute}
+	return mockRepo, NewCurrencyRegistry(config, mockRepo)
+}
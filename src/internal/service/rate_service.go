@@ -1,61 +1,373 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"exchange-rates-service/src/config"
 	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/model"
 	"exchange-rates-service/src/internal/repository"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DefaultHistoryPageSize = 100
+	MaxHistoryPageSize     = 1000
+	MaxBatchSize           = 100
 )
 
 type RateService struct {
-	supportedCurrencies map[string]bool
-	repository          repository.ExchangeRateRepository
+	config     *config.Config
+	currencies *CurrencyRegistry
+	repository repository.ExchangeRateRepository
 }
 
-func NewRateService(repo repository.ExchangeRateRepository) *RateService {
+func NewRateService(
+	config *config.Config,
+	repo repository.ExchangeRateRepository,
+	currencies *CurrencyRegistry) *RateService {
 	return &RateService{
-		supportedCurrencies: map[string]bool{
-			"EUR": true,
-			"USD": true,
-			"MXN": true,
-		},
+		config:     config,
+		currencies: currencies,
 		repository: repo,
 	}
 }
 
-func (service *RateService) StartUpdateRate(from string, to string) (string, error) {
-	if _, ok := service.supportedCurrencies[from]; !ok {
-		return "", internal.NewBadRequestError(fmt.Sprintf("currency %s not supported", from))
+func (service *RateService) StartUpdateRate(ctx context.Context, from string, to string) (string, error) {
+	if err := service.validateUpdatePair(ctx, from, to); err != nil {
+		return "", err
+	}
+
+	return service.repository.GetOrCreateRateUpdate(ctx, from, to)
+}
+
+// StartUpdateRates starts the updates of all valid pairs in one transaction.
+// Pairs which did not pass validation get the error in their result
+func (service *RateService) StartUpdateRates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]model.RateUpdateResult, error) {
+	if len(pairs) > MaxBatchSize {
+		return nil, internal.NewBadRequestError(fmt.Sprintf("batch size must not exceed %d pairs", MaxBatchSize))
+	}
+
+	results := make(map[model.CurrencyPair]model.RateUpdateResult, len(pairs))
+	validPairs := make([]model.CurrencyPair, 0, len(pairs))
+
+	for _, pair := range pairs {
+		if _, ok := results[pair]; ok {
+			continue
+		}
+
+		err := service.validateUpdatePair(ctx, pair.From, pair.To)
+		serviceError := &internal.ServiceError{}
+		if err != nil && !errors.As(err, &serviceError) {
+			return nil, err
+		}
+
+		results[pair] = model.RateUpdateResult{Error: err}
+		if err == nil {
+			validPairs = append(validPairs, pair)
+		}
+	}
+
+	if len(validPairs) == 0 {
+		return results, nil
+	}
+
+	updateIds, err := service.repository.GetOrCreateRateUpdates(ctx, validPairs)
+	if err != nil {
+		return nil, err
+	}
+
+	for pair, updateId := range updateIds {
+		results[pair] = model.RateUpdateResult{UpdateId: updateId}
+	}
+
+	return results, nil
+}
+
+func (service *RateService) validateUpdatePair(ctx context.Context, from string, to string) error {
+	if err := service.validateCurrencies(ctx, from, to); err != nil {
+		return err
+	}
+
+	if from == to {
+		return internal.NewBadRequestError(fmt.Sprintf("trying to convert same currency: %s to %s", from, to))
 	}
 
-	if _, ok := service.supportedCurrencies[to]; !ok {
-		return "", internal.NewBadRequestError(fmt.Sprintf("currency %s not supported", to))
+	return nil
+}
+
+func (service *RateService) GetRateUpdate(ctx context.Context, updateId string) (model.RateUpdate, error) {
+	return service.repository.GetRateUpdate(ctx, updateId)
+}
+
+// GetLastRate returns the stored rate of the pair. If the pair is not stored and allowDerived is set,
+// the rate is derived from the opposite direction or triangulated from the other stored pairs
+func (service *RateService) GetLastRate(ctx context.Context, from string, to string, allowDerived bool) (model.ExchangeRate, error) {
+	if err := service.validateCurrencies(ctx, from, to); err != nil {
+		return model.ExchangeRate{}, err
 	}
 
 	if from == to {
-		return "", internal.NewBadRequestError(fmt.Sprintf("trying to convert same currency: %s to %s", from, to))
+		return model.ExchangeRate{}, internal.NewBadRequestError(fmt.Sprintf("trying to get same currency rate: %s to %s", from, to))
+	}
+
+	rate, err := service.repository.GetLastRate(ctx, from, to)
+	if !allowDerived || !isMissingRate(rate, err) {
+		return rate, err
+	}
+
+	inverse, err := service.repository.GetLastRate(ctx, to, from)
+	if !isMissingRate(inverse, err) && (err != nil || !inverse.Rate.IsZero()) {
+		if err != nil {
+			return model.ExchangeRate{}, err
+		}
+		return service.invert(inverse, to, from), nil
+	}
+
+	return service.triangulate(ctx, from, to)
+}
+
+// GetLastRates returns stored rates of the pairs in the requested order. Rates are not derived.
+// Pairs which did not pass validation get the error in their result
+func (service *RateService) GetLastRates(ctx context.Context, pairs []model.CurrencyPair) ([]model.LastRateResult, error) {
+	if len(pairs) > MaxBatchSize {
+		return nil, internal.NewBadRequestError(fmt.Sprintf("batch size must not exceed %d pairs", MaxBatchSize))
 	}
 
-	return service.repository.GetOrCreateRateUpdate(from, to)
+	results := make([]model.LastRateResult, 0, len(pairs))
+	validPairs := make([]model.CurrencyPair, 0, len(pairs))
+
+	for _, pair := range pairs {
+		err := service.validateCurrencies(ctx, pair.From, pair.To)
+		if err == nil && pair.From == pair.To {
+			err = internal.NewBadRequestError(fmt.Sprintf("trying to get same currency rate: %s to %s", pair.From, pair.To))
+		}
+
+		serviceError := &internal.ServiceError{}
+		if err != nil && !errors.As(err, &serviceError) {
+			return nil, err
+		}
+
+		results = append(results, model.LastRateResult{Pair: pair, Error: err})
+		if err == nil {
+			validPairs = append(validPairs, pair)
+		}
+	}
+
+	if len(validPairs) == 0 {
+		return results, nil
+	}
+
+	rates, err := service.repository.GetLastRates(ctx, validPairs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].Error == nil {
+			results[i].Rate = rates[results[i].Pair]
+		}
+	}
+
+	return results, nil
 }
 
-func (service *RateService) GetRateUpdate(updateId string) (model.ExchangeRate, error) {
-	return service.repository.GetRateUpdate(updateId)
+func (service *RateService) invert(rate model.ExchangeRate, storedFrom string, storedTo string) model.ExchangeRate {
+	inverted := invertRate(*rate.Rate, service.config.InverseRatePrecision)
+	return model.ExchangeRate{
+		Rate:           &inverted,
+		UpdateDateTime: rate.UpdateDateTime,
+		Provider:       rate.Provider,
+		Derived:        model.DerivationInverse,
+		Legs: []model.ExchangeRateLeg{
+			{
+				FromCurrency:   storedTo,
+				ToCurrency:     storedFrom,
+				Rate:           inverted,
+				UpdateDateTime: *rate.UpdateDateTime,
+				Provider:       rate.Provider,
+				Inverted:       true,
+			},
+		},
+	}
 }
 
-func (service *RateService) GetLastRate(from string, to string) (model.ExchangeRate, error) {
-	if _, ok := service.supportedCurrencies[from]; !ok {
-		return model.ExchangeRate{}, internal.NewBadRequestError(fmt.Sprintf("currency %s not supported", from))
+// Convert converts amount using the last rate of the pair. The result is rounded to the minor units of the to currency.
+// Default rounding mode from config is used when roundingMode is empty
+func (service *RateService) Convert(
+	ctx context.Context,
+	from string,
+	to string,
+	amount decimal.Decimal,
+	roundingMode model.RoundingMode) (model.Conversion, error) {
+	if roundingMode == "" {
+		roundingMode = service.config.ConversionRoundingMode
 	}
 
-	if _, ok := service.supportedCurrencies[to]; !ok {
-		return model.ExchangeRate{}, internal.NewBadRequestError(fmt.Sprintf("currency %s not supported", to))
+	rate, err := service.GetLastRate(ctx, from, to, true)
+	if err != nil {
+		return model.Conversion{}, err
+	}
+
+	if rate.Rate == nil {
+		return model.Conversion{}, internal.NewNotFoundError("rate updates not found")
+	}
+
+	toCurrency, err := service.currencies.GetActive(ctx, to)
+	if err != nil {
+		return model.Conversion{}, err
+	}
+
+	minorUnits := toCurrency.MinorUnits
+	conversion := model.Conversion{
+		Amount:       amount,
+		Result:       roundingMode.Round(amount.Mul(*rate.Rate), minorUnits),
+		MinorUnits:   minorUnits,
+		RoundingMode: roundingMode,
+		Rate:         rate,
+	}
+
+	return conversion, nil
+}
+
+// GetCurrencies returns active currencies with the pairs from them that have a stored rate
+func (service *RateService) GetCurrencies(ctx context.Context) ([]model.CurrencyRates, error) {
+	currencies, err := service.currencies.GetCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := service.repository.GetAllRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ratesByCurrency := make(map[string][]model.ExchangeRateDbo)
+	for _, rate := range rates {
+		if rate.UpdateTime == nil {
+			continue
+		}
+		ratesByCurrency[rate.FromCurrency] = append(ratesByCurrency[rate.FromCurrency], rate)
+	}
+
+	result := make([]model.CurrencyRates, 0, len(currencies))
+	for _, currency := range currencies {
+		if !currency.Active {
+			continue
+		}
+
+		currencyRates := ratesByCurrency[currency.Code]
+		slices.SortFunc(currencyRates, func(a, b model.ExchangeRateDbo) int {
+			return strings.Compare(a.ToCurrency, b.ToCurrency)
+		})
+
+		result = append(result, model.CurrencyRates{
+			Currency: currency,
+			Rates:    currencyRates,
+		})
+	}
+
+	return result, nil
+}
+
+// GetRateHistory returns the page of rates stored for the pair in [since, until) ordered by update time.
+// cursor is the NextCursor value of the previous page, empty for the first page
+func (service *RateService) GetRateHistory(
+	ctx context.Context,
+	from string,
+	to string,
+	since *time.Time,
+	until *time.Time,
+	cursor string,
+	limit int) (model.RateHistoryPage, error) {
+	if err := service.validateCurrencies(ctx, from, to); err != nil {
+		return model.RateHistoryPage{}, err
 	}
 
 	if from == to {
-		return model.ExchangeRate{}, internal.NewBadRequestError(fmt.Sprintf("trying to get same currency rate: %s to %s", from, to))
+		return model.RateHistoryPage{}, internal.NewBadRequestError(fmt.Sprintf("trying to get same currency rate: %s to %s", from, to))
 	}
 
-	return service.repository.GetLastRate(from, to)
+	if since != nil && until != nil && !since.Before(*until) {
+		return model.RateHistoryPage{}, internal.NewBadRequestError("since must be before until")
+	}
+
+	if limit == 0 {
+		limit = DefaultHistoryPageSize
+	}
+
+	if limit < 0 || limit > MaxHistoryPageSize {
+		return model.RateHistoryPage{}, internal.NewBadRequestError(fmt.Sprintf("limit must be between 1 and %d", MaxHistoryPageSize))
+	}
+
+	var after *model.RateHistoryCursor
+	if cursor != "" {
+		decoded, err := DecodeHistoryCursor(cursor)
+		if err != nil {
+			return model.RateHistoryPage{}, err
+		}
+		after = &decoded
+	}
+
+	return service.repository.GetRateHistory(ctx, from, to, since, until, after, limit)
+}
+
+func isNotFound(err error) bool {
+	serviceError := &internal.ServiceError{}
+	return errors.As(err, &serviceError) && serviceError.ErrorType == internal.NotFound
+}
+
+func isMissingRate(rate model.ExchangeRate, err error) bool {
+	return isNotFound(err) || (err == nil && rate.Rate == nil)
+}
+
+func (service *RateService) validateCurrencies(ctx context.Context, from string, to string) error {
+	if _, err := service.currencies.GetActive(ctx, from); err != nil {
+		return err
+	}
+
+	_, err := service.currencies.GetActive(ctx, to)
+	return err
+}
+
+// EncodeHistoryCursor converts the cursor into an opaque string which can be passed to GetRateHistory
+func EncodeHistoryCursor(cursor model.RateHistoryCursor) string {
+	value := fmt.Sprintf("%d:%d", cursor.UpdateTime.UnixNano(), cursor.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func DecodeHistoryCursor(cursor string) (model.RateHistoryCursor, error) {
+	invalidCursorError := internal.NewBadRequestError("invalid cursor")
+
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return model.RateHistoryCursor{}, invalidCursorError
+	}
+
+	updateTimeValue, idValue, ok := strings.Cut(string(value), ":")
+	if !ok {
+		return model.RateHistoryCursor{}, invalidCursorError
+	}
+
+	updateTime, err := strconv.ParseInt(updateTimeValue, 10, 64)
+	if err != nil {
+		return model.RateHistoryCursor{}, invalidCursorError
+	}
+
+	id, err := strconv.ParseInt(idValue, 10, 64)
+	if err != nil {
+		return model.RateHistoryCursor{}, invalidCursorError
+	}
 
+	return model.RateHistoryCursor{
+		UpdateTime: time.Unix(0, updateTime).UTC(),
+		Id:         id,
+	}, nil
 }
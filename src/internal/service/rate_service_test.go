@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
+	"exchange-rates-service/src/config"
 	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/model"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -14,40 +17,97 @@ type mockRepository struct {
 	mock.Mock
 }
 
-func (m *mockRepository) GetOrCreateRateUpdate(from string, to string) (string, error) {
+func (m *mockRepository) GetOrCreateRateUpdate(ctx context.Context, from string, to string) (string, error) {
 	args := m.Called(from, to)
 	return args.String(0), args.Error(1)
 }
 
-func (m *mockRepository) GetRateUpdate(updateId string) (model.ExchangeRate, error) {
+func (m *mockRepository) GetOrCreateRateUpdates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]string, error) {
+	args := m.Called(pairs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[model.CurrencyPair]string), args.Error(1)
+}
+
+func (m *mockRepository) GetRateUpdate(ctx context.Context, updateId string) (model.RateUpdate, error) {
 	args := m.Called(updateId)
-	return args.Get(0).(model.ExchangeRate), args.Error(1)
+	return args.Get(0).(model.RateUpdate), args.Error(1)
 }
 
-func (m *mockRepository) GetRatesForUpdate(fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
-	args := m.Called(fetchSize)
+func (m *mockRepository) GetRatesForUpdate(ctx context.Context, workerId string, leaseDuration time.Duration, fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
+	args := m.Called(workerId, leaseDuration, fetchSize)
 	return args.Get(0).([]model.ExchangeRateUpdateDbo), args.Error(1)
 }
 
-func (m *mockRepository) SetUpdateError(updateId string) error {
-	args := m.Called(updateId)
+func (m *mockRepository) SetUpdateError(ctx context.Context, updateId string, workerId string, errorMessage string) error {
+	args := m.Called(updateId, workerId, errorMessage)
+	return args.Error(0)
+}
+
+func (m *mockRepository) ScheduleUpdateRetry(ctx context.Context, updateId string, workerId string, errorMessage string, nextAttemptAt time.Time) error {
+	args := m.Called(updateId, workerId, errorMessage, nextAttemptAt)
 	return args.Error(0)
 }
 
-func (m *mockRepository) UpdateRate(updateId string, from string, to string, rate decimal.Decimal) error {
-	args := m.Called(updateId, from, to, rate)
+func (m *mockRepository) PostponeUpdate(ctx context.Context, updateId string, workerId string, nextAttemptAt time.Time) error {
+	args := m.Called(updateId, workerId, nextAttemptAt)
 	return args.Error(0)
 }
 
-func (m *mockRepository) GetLastRate(from string, to string) (model.ExchangeRate, error) {
+func (m *mockRepository) ReclaimStuckUpdates(ctx context.Context, maxAttempts int, maxAge time.Duration) (model.ReclaimResult, error) {
+	args := m.Called(maxAttempts, maxAge)
+	return args.Get(0).(model.ReclaimResult), args.Error(1)
+}
+
+func (m *mockRepository) UpdateRate(ctx context.Context, updateId string, workerId string, from string, to string, quote model.RateQuote) error {
+	args := m.Called(updateId, workerId, from, to, quote)
+	return args.Error(0)
+}
+
+func (m *mockRepository) GetLastRate(ctx context.Context, from string, to string) (model.ExchangeRate, error) {
 	args := m.Called(from, to)
 	return args.Get(0).(model.ExchangeRate), args.Error(1)
 }
 
+func (m *mockRepository) GetLastRates(ctx context.Context, pairs []model.CurrencyPair) (map[model.CurrencyPair]model.ExchangeRate, error) {
+	args := m.Called(pairs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[model.CurrencyPair]model.ExchangeRate), args.Error(1)
+}
+
+func (m *mockRepository) GetAllRates(ctx context.Context) ([]model.ExchangeRateDbo, error) {
+	args := m.Called()
+	return args.Get(0).([]model.ExchangeRateDbo), args.Error(1)
+}
+
+func (m *mockRepository) GetRateHistory(
+	ctx context.Context,
+	from string,
+	to string,
+	since *time.Time,
+	until *time.Time,
+	cursor *model.RateHistoryCursor,
+	limit int) (model.RateHistoryPage, error) {
+	args := m.Called(from, to, since, until, cursor, limit)
+	return args.Get(0).(model.RateHistoryPage), args.Error(1)
+}
+
 func TestStartUpdateRate_ThrowsErrorWhenUnknownCurrency(t *testing.T) {
 	service := createMockService()
 
-	updateId, err := service.StartUpdateRate("UNKNOWN", "USD")
+	updateId, err := service.StartUpdateRate(context.Background(), "UNKNOWN", "USD")
+	assert.Equal(t, updateId, "")
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+}
+
+func TestStartUpdateRate_ThrowsErrorWhenCurrencyDisabled(t *testing.T) {
+	service := createMockService()
+
+	updateId, err := service.StartUpdateRate(context.Background(), "GBP", "USD")
 	assert.Equal(t, updateId, "")
 	assert.Error(t, err)
 	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
@@ -56,16 +116,85 @@ func TestStartUpdateRate_ThrowsErrorWhenUnknownCurrency(t *testing.T) {
 func TestStartUpdateRate_ThrowsErrorOnConvertingSameCurrency(t *testing.T) {
 	service := createMockService()
 
-	updateId, err := service.StartUpdateRate("USD", "USD")
+	updateId, err := service.StartUpdateRate(context.Background(), "USD", "USD")
 	assert.Equal(t, updateId, "")
 	assert.Error(t, err)
 	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
 }
 
+func TestStartUpdateRates_ShouldReportInvalidPairsPerItem(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	valid := model.CurrencyPair{From: "USD", To: "EUR"}
+	unknown := model.CurrencyPair{From: "UNKNOWN", To: "EUR"}
+	same := model.CurrencyPair{From: "USD", To: "USD"}
+
+	mockRepo.On("GetOrCreateRateUpdates", []model.CurrencyPair{valid}).
+		Return(map[model.CurrencyPair]string{valid: "update-1"}, nil)
+
+	results, err := service.StartUpdateRates(context.Background(), []model.CurrencyPair{valid, unknown, same, valid})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, model.RateUpdateResult{UpdateId: "update-1"}, results[valid])
+	assert.Equal(t, results[unknown].Error.(*internal.ServiceError).ErrorType, internal.BadRequest)
+	assert.Equal(t, results[same].Error.(*internal.ServiceError).ErrorType, internal.BadRequest)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartUpdateRates_ShouldNotCallRepositoryWhenAllPairsInvalid(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	results, err := service.StartUpdateRates(context.Background(), []model.CurrencyPair{{From: "GBP", To: "EUR"}})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	mockRepo.AssertNotCalled(t, "GetOrCreateRateUpdates", mock.Anything)
+}
+
+func TestStartUpdateRates_ThrowsErrorWhenBatchTooLarge(t *testing.T) {
+	service := createMockService()
+
+	pairs := make([]model.CurrencyPair, MaxBatchSize+1)
+	_, err := service.StartUpdateRates(context.Background(), pairs)
+
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+}
+
+func TestGetLastRates_ShouldReturnResultsInRequestedOrder(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	found := model.CurrencyPair{From: "USD", To: "EUR"}
+	missing := model.CurrencyPair{From: "USD", To: "MXN"}
+	invalid := model.CurrencyPair{From: "UNKNOWN", To: "EUR"}
+	updateTime := time.Now().UTC()
+	rateValue := decimal.NewFromFloat(0.9)
+
+	mockRepo.On("GetLastRates", []model.CurrencyPair{found, missing}).
+		Return(map[model.CurrencyPair]model.ExchangeRate{
+			found: {Rate: &rateValue, UpdateDateTime: &updateTime},
+		}, nil)
+
+	results, err := service.GetLastRates(context.Background(), []model.CurrencyPair{invalid, found, missing})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, invalid, results[0].Pair)
+	assert.Equal(t, results[0].Error.(*internal.ServiceError).ErrorType, internal.BadRequest)
+	assert.Equal(t, found, results[1].Pair)
+	assert.Equal(t, &rateValue, results[1].Rate.Rate)
+	assert.NoError(t, results[1].Error)
+	assert.Equal(t, missing, results[2].Pair)
+	assert.Nil(t, results[2].Rate.Rate)
+	assert.NoError(t, results[2].Error)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetLastRate_ThrowsErrorWhenUnknownCurrency(t *testing.T) {
 	service := createMockService()
 
-	_, err := service.GetLastRate("UNKNOWN", "USD")
+	_, err := service.GetLastRate(context.Background(), "UNKNOWN", "USD", true)
 	assert.Error(t, err)
 	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
 }
@@ -73,13 +202,141 @@ func TestGetLastRate_ThrowsErrorWhenUnknownCurrency(t *testing.T) {
 func TestGetLastRate_ThrowsErrorOnConvertingSameCurrency(t *testing.T) {
 	service := createMockService()
 
-	_, err := service.GetLastRate("EUR", "EUR")
+	_, err := service.GetLastRate(context.Background(), "EUR", "EUR", true)
 	assert.Error(t, err)
 	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
 }
 
+func TestGetRateHistory_ThrowsErrorWhenSinceIsNotBeforeUntil(t *testing.T) {
+	service := createMockService()
+
+	since := time.Now().UTC()
+	until := since.Add(-time.Hour)
+
+	_, err := service.GetRateHistory(context.Background(), "USD", "EUR", &since, &until, "", 0)
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+}
+
+func TestGetRateHistory_ThrowsErrorWhenLimitTooLarge(t *testing.T) {
+	service := createMockService()
+
+	_, err := service.GetRateHistory(context.Background(), "USD", "EUR", nil, nil, "", MaxHistoryPageSize+1)
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+}
+
+func TestGetRateHistory_ThrowsErrorOnInvalidCursor(t *testing.T) {
+	service := createMockService()
+
+	_, err := service.GetRateHistory(context.Background(), "USD", "EUR", nil, nil, "not a cursor", 0)
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+}
+
+func TestGetRateHistory_ShouldDecodeCursorAndUseDefaultLimit(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	cursor := model.RateHistoryCursor{UpdateTime: time.Date(2025, 3, 1, 12, 0, 0, 123000, time.UTC), Id: 42}
+	expectedPage := model.RateHistoryPage{Rates: []model.ExchangeRate{}}
+
+	mockRepo.On("GetRateHistory", "USD", "EUR", (*time.Time)(nil), (*time.Time)(nil), &cursor, DefaultHistoryPageSize).
+		Return(expectedPage, nil)
+
+	page, err := service.GetRateHistory(context.Background(), "USD", "EUR", nil, nil, EncodeHistoryCursor(cursor), 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvert_ShouldRoundToMinorUnitsWithDefaultMode(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	rateValue := decimal.RequireFromString("17.125")
+	updateTime := time.Now().UTC()
+	rate := model.ExchangeRate{Rate: &rateValue, UpdateDateTime: &updateTime}
+	mockRepo.On("GetLastRate", "USD", "MXN").Return(rate, nil)
+
+	conversion, err := service.Convert(context.Background(), "USD", "MXN", decimal.RequireFromString("1.5"), "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "25.69", conversion.Result.String())
+	assert.Equal(t, int32(2), conversion.MinorUnits)
+	assert.Equal(t, model.RoundingHalfEven, conversion.RoundingMode)
+	assert.Equal(t, rate, conversion.Rate)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvert_ShouldUseRequestedRoundingMode(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	rateValue := decimal.RequireFromString("0.125")
+	updateTime := time.Now().UTC()
+	mockRepo.On("GetLastRate", "USD", "EUR").
+		Return(model.ExchangeRate{Rate: &rateValue, UpdateDateTime: &updateTime}, nil)
+
+	halfUp, err := service.Convert(context.Background(), "USD", "EUR", decimal.NewFromInt(1), model.RoundingHalfUp)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.13", halfUp.Result.String())
+
+	halfEven, err := service.Convert(context.Background(), "USD", "EUR", decimal.NewFromInt(1), model.RoundingHalfEven)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.12", halfEven.Result.String())
+
+	truncated, err := service.Convert(context.Background(), "USD", "EUR", decimal.RequireFromString("1.07"), model.RoundingTruncate)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.13", truncated.Result.String())
+}
+
+func TestConvert_ThrowsErrorWhenUnknownCurrency(t *testing.T) {
+	service := createMockService()
+
+	_, err := service.Convert(context.Background(), "UNKNOWN", "USD", decimal.NewFromInt(1), "")
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.BadRequest)
+}
+
+func TestGetCurrencies_ShouldReturnActiveCurrenciesWithStoredPairs(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	updateTime := time.Now().UTC()
+	rate := decimal.NewFromFloat(1.1)
+	mockRepo.On("GetAllRates").Return([]model.ExchangeRateDbo{
+		{FromCurrency: "EUR", ToCurrency: "USD", RateValue: &rate, UpdateTime: &updateTime},
+		{FromCurrency: "EUR", ToCurrency: "MXN", RateValue: &rate, UpdateTime: &updateTime},
+		{FromCurrency: "USD", ToCurrency: "EUR", RateValue: &rate, UpdateTime: &updateTime},
+	}, nil)
+
+	currencies, err := service.GetCurrencies(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, currencies, 3)
+	assert.Equal(t, "EUR", currencies[0].Currency.Code)
+	assert.Len(t, currencies[0].Rates, 2)
+	assert.Equal(t, "MXN", currencies[0].Rates[0].ToCurrency)
+	assert.Equal(t, "USD", currencies[0].Rates[1].ToCurrency)
+	assert.Equal(t, "MXN", currencies[1].Currency.Code)
+	assert.Empty(t, currencies[1].Rates)
+	assert.Equal(t, "USD", currencies[2].Currency.Code)
+	assert.Len(t, currencies[2].Rates, 1)
+	mockRepo.AssertExpectations(t)
+}
+
 func createMockService() *RateService {
+	_, service := createMockServiceWithRepository()
+	return service
+}
+
+func createMockServiceWithRepository() (*mockRepository, *RateService) {
 	mockRepo := new(mockRepository)
-	return NewRateService(mockRepo)
+	mockCurrencyRepo := new(mockCurrencyRepository)
+	mockCurrencyRepo.On("GetCurrencies").Return(testCurrencies, nil)
 
+	config := &config.Config{
+		ConversionRoundingMode:  model.RoundingHalfEven,
+		InverseRatePrecision:    6,
+		CurrencyRefreshInterval: time.Minute,
+	}
+	return mockRepo, NewRateService(config, mockRepo, NewCurrencyRegistry(config, mockCurrencyRepo))
 }
@@ -1,12 +1,24 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/integration"
+	"exchange-rates-service/src/internal/model"
 	"exchange-rates-service/src/internal/repository"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
 )
 
+// releaseTimeout bounds the release of updates after the processing was cancelled
+const releaseTimeout = 5 * time.Second
+
 type RateServiceWorker struct {
 	config     *config.Config
 	repository repository.ExchangeRateRepository
@@ -24,27 +36,234 @@ func NewRateServiceWorker(config *config.Config, repo repository.ExchangeRateRep
 	return &serviceWorker
 }
 
-func (s *RateServiceWorker) ExecuteUpdate() (int, error) {
-	rateUpdates, err := s.repository.GetRatesForUpdate(s.config.WorkerFetchSize)
+// Run executes the updates on every tick and every notification until ctx is done. The updates run with workCtx,
+// so the current batch is not cancelled together with ctx. Notifications queued meanwhile are drained,
+// since the updates claimed after them cover the notified ones
+func (s *RateServiceWorker) Run(ctx context.Context, workCtx context.Context, ticks <-chan time.Time, notifications <-chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+		case <-notifications:
+		}
+
+		for len(notifications) > 0 {
+			<-notifications
+		}
+
+		for ctx.Err() == nil {
+			updated, err := s.ExecuteUpdate(workCtx)
+			if err != nil {
+				log.Println(err)
+			}
+
+			if updated == 0 {
+				break
+			}
+
+			log.Printf("Updated %d rates", updated)
+		}
+	}
+}
+
+// ExecuteUpdate processes the claimed updates grouped by the from currency, so every group needs one provider call.
+// Groups are processed in parallel, at most WorkerConcurrency at once.
+// The claimed updates are processed within the lease duration, rates stored after the lease expired are rejected anyway.
+// When the context is done, the updates which are not processed yet are released, so other workers pick them at once.
+// Returns the number of processed updates and all repository errors joined
+func (s *RateServiceWorker) ExecuteUpdate(ctx context.Context) (int, error) {
+	rateUpdates, err := s.repository.GetRatesForUpdate(ctx, s.config.WorkerId, s.config.WorkerLeaseDuration, s.config.WorkerFetchSize)
 	if err != nil {
 		return 0, err
 	}
-	updateCount := 0
 
+	ctx, cancel := context.WithTimeout(ctx, s.config.WorkerLeaseDuration)
+	defer cancel()
+
+	var (
+		wg          sync.WaitGroup
+		mutex       sync.Mutex
+		updateCount int
+		errs        []error
+	)
+	semaphore := make(chan struct{}, max(s.config.WorkerConcurrency, 1))
+
+	groups := groupByFromCurrency(rateUpdates)
+	started := 0
+	for _, group := range groups {
+		if ctx.Err() != nil {
+			break
+		}
+
+		semaphore <- struct{}{}
+		started++
+		wg.Go(func() {
+			defer func() { <-semaphore }()
+
+			updated, err := s.executeRateUpdates(ctx, group)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			updateCount += updated
+			errs = append(errs, err...)
+		})
+	}
+
+	wg.Wait()
+
+	if started < len(groups) {
+		s.releaseUpdates(ctx, slices.Concat(groups[started:]...))
+		errs = append(errs, ctx.Err())
+	}
+
+	return updateCount, errors.Join(errs...)
+}
+
+// executeRateUpdates fetches rates of the updates with the same from currency and stores them one by one.
+// Provider errors are handled by the retry policy, so only repository errors are returned.
+// Updates are released when the context is done, no retry is scheduled for the cancelled call
+func (s *RateServiceWorker) executeRateUpdates(ctx context.Context, rateUpdates []model.ExchangeRateUpdateDbo) (int, []error) {
+	base := rateUpdates[0].FromCurrency
+	targets := make([]string, 0, len(rateUpdates))
 	for _, rateUpdate := range rateUpdates {
-		rate, err := s.client.GetRate(rateUpdate.FromCurrency, rateUpdate.ToCurrency)
-		if err != nil {
-			s.repository.SetUpdateError(rateUpdate.Id)
+		targets = append(targets, rateUpdate.ToCurrency)
+	}
+
+	rates, err := s.client.GetRates(ctx, base, targets)
+	if ctx.Err() != nil {
+		s.releaseUpdates(ctx, rateUpdates)
+		return 0, []error{ctx.Err()}
+	}
+
+	// Rates of the other targets are stored, the missing targets get their errors
+	var partial *integration.PartialRatesError
+	if errors.As(err, &partial) {
+		log.Println(err)
+		err = nil
+	}
+
+	if errors.Is(err, integration.ErrCircuitOpen) {
+		log.Printf("Updates of %s postponed: %s", base, err)
+		s.postponeUpdates(ctx, rateUpdates, s.config.BreakerCoolDown)
+		return len(rateUpdates), nil
+	}
+
+	if errors.Is(err, integration.ErrQuotaExhausted) {
+		log.Printf("Updates of %s paused: %s", base, err)
+		s.postponeUpdates(ctx, rateUpdates, s.config.WorkerRetryMaxDelay)
+		return len(rateUpdates), nil
+	}
+
+	if err != nil {
+		log.Println(err)
+		for _, rateUpdate := range rateUpdates {
+			s.handleUpdateError(ctx, rateUpdate, err)
+		}
+		return len(rateUpdates), nil
+	}
+
+	updateCount := 0
+	var errs []error
+	for i, rateUpdate := range rateUpdates {
+		rate, ok := rates[rateUpdate.ToCurrency]
+		if !ok {
+			// The client explains the missing targets, the update fails without retries only when every asked
+			// provider does not support the target
+			err := fmt.Errorf("rate %s/%s not returned", rateUpdate.FromCurrency, rateUpdate.ToCurrency)
+			if partial != nil && partial.Errors[rateUpdate.ToCurrency] != nil {
+				err = partial.Errors[rateUpdate.ToCurrency]
+			}
 			log.Println(err)
+			s.handleUpdateError(ctx, rateUpdate, err)
 			updateCount++
 			continue
 		}
 
-		if err := s.repository.UpdateRate(rateUpdate.Id, rateUpdate.FromCurrency, rateUpdate.ToCurrency, rate); err != nil {
-			return updateCount, err
+		err = s.repository.UpdateRate(ctx, rateUpdate.Id, s.config.WorkerId, rateUpdate.FromCurrency, rateUpdate.ToCurrency, rate)
+		if errors.Is(err, internal.ErrLeaseLost) {
+			log.Printf("Lease of update %s lost, the rate is not stored", rateUpdate.Id)
+			continue
+		}
+
+		if err != nil && ctx.Err() != nil {
+			s.releaseUpdates(ctx, rateUpdates[i:])
+			return updateCount, append(errs, ctx.Err())
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
 		updateCount++
 	}
 
-	return updateCount, nil
+	return updateCount, errs
+}
+
+// groupByFromCurrency keeps the order of the first update of every currency
+func groupByFromCurrency(rateUpdates []model.ExchangeRateUpdateDbo) [][]model.ExchangeRateUpdateDbo {
+	groups := make([][]model.ExchangeRateUpdateDbo, 0)
+	indexes := make(map[string]int)
+	for _, rateUpdate := range rateUpdates {
+		index, ok := indexes[rateUpdate.FromCurrency]
+		if !ok {
+			index = len(groups)
+			indexes[rateUpdate.FromCurrency] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], rateUpdate)
+	}
+	return groups
+}
+
+// ReclaimStuckUpdates returns the updates abandoned by crashed workers to the queue.
+// Updates older than the max update age or without attempts left get the error status
+func (s *RateServiceWorker) ReclaimStuckUpdates(ctx context.Context) (model.ReclaimResult, error) {
+	return s.repository.ReclaimStuckUpdates(ctx, s.config.WorkerMaxAttempts, s.config.WorkerMaxUpdateAge)
+}
+
+// handleUpdateError schedules the next attempt of the update. The update gets the error status after the last attempt,
+// or at once when the provider error is permanent
+func (s *RateServiceWorker) handleUpdateError(ctx context.Context, rateUpdate model.ExchangeRateUpdateDbo, err error) {
+	attempts := rateUpdate.Attempts + 1
+	if attempts >= s.config.WorkerMaxAttempts || integration.IsPermanent(err) {
+		s.repository.SetUpdateError(ctx, rateUpdate.Id, s.config.WorkerId, err.Error())
+		return
+	}
+
+	nextAttemptAt := time.Now().UTC().Add(s.retryDelay(attempts))
+	s.repository.ScheduleUpdateRetry(ctx, rateUpdate.Id, s.config.WorkerId, err.Error(), nextAttemptAt)
+}
+
+// postponeUpdates returns the updates to the queue without counting an attempt, they are picked again after the delay
+func (s *RateServiceWorker) postponeUpdates(ctx context.Context, rateUpdates []model.ExchangeRateUpdateDbo, delay time.Duration) {
+	nextAttemptAt := time.Now().UTC().Add(delay)
+	for _, rateUpdate := range rateUpdates {
+		s.repository.PostponeUpdate(ctx, rateUpdate.Id, s.config.WorkerId, nextAttemptAt)
+	}
+}
+
+// releaseUpdates returns the claimed updates to the queue when their processing is cancelled, so they are picked
+// without waiting for the lease expiration. The release is not cancelled with the context
+func (s *RateServiceWorker) releaseUpdates(ctx context.Context, rateUpdates []model.ExchangeRateUpdateDbo) {
+	log.Printf("Releasing %d updates: %s", len(rateUpdates), ctx.Err())
+
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+	s.postponeUpdates(releaseCtx, rateUpdates, 0)
+}
+
+// retryDelay doubles the base delay for every failed attempt up to the max delay.
+// The delay is randomized between half and full value, so updates failed together are not retried together
+func (s *RateServiceWorker) retryDelay(attempts int) time.Duration {
+	delay := s.config.WorkerRetryMaxDelay
+	if shift := attempts - 1; shift < 32 {
+		if exponential := s.config.WorkerRetryBaseDelay << shift; exponential > 0 && exponential < delay {
+			delay = exponential
+		}
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
 }
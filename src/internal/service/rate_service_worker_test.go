@@ -1,10 +1,16 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"exchange-rates-service/src/config"
+	"exchange-rates-service/src/internal"
+	"exchange-rates-service/src/internal/integration"
 	"exchange-rates-service/src/internal/model"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -15,12 +21,12 @@ type mockApiClient struct {
 	mock.Mock
 }
 
-func (m *mockApiClient) GetRate(from string, to string) (decimal.Decimal, error) {
-	args := m.Called(from, to)
-	return args.Get(0).(decimal.Decimal), args.Error(1)
+func (m *mockApiClient) GetRates(ctx context.Context, base string, targets []string) (map[string]model.RateQuote, error) {
+	args := m.Called(base, targets)
+	return args.Get(0).(map[string]model.RateQuote), args.Error(1)
 }
 
-func TestExecuteUpdate_ShouldSetErrorWhenReturnedErrorFromApi(t *testing.T) {
+func TestExecuteUpdate_ShouldSetErrorWhenReturnedErrorFromApiOnLastAttempt(t *testing.T) {
 	mockRepo, mockClient, worker := createMocks()
 
 	rateUpdate := model.ExchangeRateUpdateDbo{
@@ -28,14 +34,15 @@ func TestExecuteUpdate_ShouldSetErrorWhenReturnedErrorFromApi(t *testing.T) {
 		FromCurrency: "USD",
 		ToCurrency:   "EUR",
 		Status:       model.StatusUpdating,
+		Attempts:     2,
 	}
 
-	mockRepo.On("GetRatesForUpdate", 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
-	mockClient.On("GetRate", rateUpdate.FromCurrency, rateUpdate.ToCurrency).
-		Return(decimal.Decimal{}, errors.New("api error"))
-	mockRepo.On("SetUpdateError", rateUpdate.Id).Return(nil)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
+	mockClient.On("GetRates", rateUpdate.FromCurrency, []string{rateUpdate.ToCurrency}).
+		Return(map[string]model.RateQuote(nil), errors.New("api error"))
+	mockRepo.On("SetUpdateError", rateUpdate.Id, "worker-1", "api error").Return(nil)
 
-	count, err := worker.ExecuteUpdate()
+	count, err := worker.ExecuteUpdate(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, count)
@@ -43,6 +50,420 @@ func TestExecuteUpdate_ShouldSetErrorWhenReturnedErrorFromApi(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestExecuteUpdate_ShouldScheduleRetryWhenReturnedErrorFromApi(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	rateUpdate := model.ExchangeRateUpdateDbo{
+		Id:           "update-id-1",
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Status:       model.StatusUpdating,
+		Attempts:     1,
+	}
+
+	before := time.Now().UTC()
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
+	mockClient.On("GetRates", rateUpdate.FromCurrency, []string{rateUpdate.ToCurrency}).
+		Return(map[string]model.RateQuote(nil), errors.New("api error"))
+	mockRepo.On("ScheduleUpdateRetry", rateUpdate.Id, "worker-1", "api error", mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		// Second attempt waits for 2 base delays with jitter
+		return !nextAttemptAt.Before(before.Add(time.Second)) && !nextAttemptAt.After(time.Now().UTC().Add(2*time.Second))
+	})).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	mockRepo.AssertNotCalled(t, "SetUpdateError", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldSetErrorWhenProviderErrorIsPermanent(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	rateUpdate := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "XXX"}
+	providerError := &integration.ProviderError{
+		Provider: "currency-api",
+		Message:  "rate USD/XXX not found",
+		Err:      integration.ErrSymbolUnsupported,
+	}
+
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
+	mockClient.On("GetRates", "USD", []string{"XXX"}).Return(map[string]model.RateQuote(nil), providerError)
+	mockRepo.On("SetUpdateError", rateUpdate.Id, "worker-1", providerError.Error()).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	mockRepo.AssertNotCalled(t, "ScheduleUpdateRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldPostponeUpdatesWhenCircuitOpen(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR", Attempts: 2}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "USD", ToCurrency: "MXN", Attempts: 2}
+
+	before := time.Now().UTC()
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR", "MXN"}).
+		Return(map[string]model.RateQuote(nil), fmt.Errorf("currency-api: %w", integration.ErrCircuitOpen))
+	nextAttemptAfterCoolDown := mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return !nextAttemptAt.Before(before.Add(time.Minute)) && !nextAttemptAt.After(time.Now().UTC().Add(time.Minute))
+	})
+	mockRepo.On("PostponeUpdate", update1.Id, "worker-1", nextAttemptAfterCoolDown).Return(nil)
+	mockRepo.On("PostponeUpdate", update2.Id, "worker-1", nextAttemptAfterCoolDown).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertNotCalled(t, "SetUpdateError", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ScheduleUpdateRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldPauseUpdatesWhenQuotaExhausted(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	rateUpdate := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+
+	before := time.Now().UTC()
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR"}).
+		Return(map[string]model.RateQuote(nil), fmt.Errorf("exchangeratesapi.io: %w", integration.ErrQuotaExhausted))
+	mockRepo.On("PostponeUpdate", rateUpdate.Id, "worker-1", mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return !nextAttemptAt.Before(before.Add(10*time.Second)) && !nextAttemptAt.After(time.Now().UTC().Add(10*time.Second))
+	})).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	mockRepo.AssertNotCalled(t, "ScheduleUpdateRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldReleaseUpdatesWithoutRetryWhenCancelled(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rateUpdate := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+
+	before := time.Now().UTC()
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR"}).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return(map[string]model.RateQuote(nil), context.Canceled)
+	mockRepo.On("PostponeUpdate", rateUpdate.Id, "worker-1", mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return !nextAttemptAt.Before(before) && !nextAttemptAt.After(time.Now().UTC())
+	})).Return(nil)
+
+	count, err := worker.ExecuteUpdate(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, count)
+	mockRepo.AssertNotCalled(t, "ScheduleUpdateRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "SetUpdateError", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldReleaseNotStartedUpdatesWhenCancelled(t *testing.T) {
+	mockRepo, _, worker := createMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "EUR", ToCurrency: "MXN"}
+
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockRepo.On("PostponeUpdate", update1.Id, "worker-1", mock.Anything).Return(nil)
+	mockRepo.On("PostponeUpdate", update2.Id, "worker-1", mock.Anything).Return(nil)
+
+	count, err := worker.ExecuteUpdate(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, count)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldSkipUpdateWhenLeaseLost(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "EUR", ToCurrency: "MXN"}
+
+	rate1, rate2 := newQuote(1.18), newQuote(20.5)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockClient.On("GetRates", update1.FromCurrency, []string{update1.ToCurrency}).Return(map[string]model.RateQuote{update1.ToCurrency: rate1}, nil)
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", update1.FromCurrency, update1.ToCurrency, rate1).Return(internal.ErrLeaseLost)
+	mockClient.On("GetRates", update2.FromCurrency, []string{update2.ToCurrency}).Return(map[string]model.RateQuote{update2.ToCurrency: rate2}, nil)
+	mockRepo.On("UpdateRate", update2.Id, "worker-1", update2.FromCurrency, update2.ToCurrency, rate2).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldJoinErrorsOfAllUpdates(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "EUR", ToCurrency: "MXN"}
+	error1, error2 := errors.New("database error 1"), errors.New("database error 2")
+
+	rate1, rate2 := newQuote(1.18), newQuote(20.5)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockClient.On("GetRates", update1.FromCurrency, []string{update1.ToCurrency}).Return(map[string]model.RateQuote{update1.ToCurrency: rate1}, nil)
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", update1.FromCurrency, update1.ToCurrency, rate1).Return(error1)
+	mockClient.On("GetRates", update2.FromCurrency, []string{update2.ToCurrency}).Return(map[string]model.RateQuote{update2.ToCurrency: rate2}, nil)
+	mockRepo.On("UpdateRate", update2.Id, "worker-1", update2.FromCurrency, update2.ToCurrency, rate2).Return(error2)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.ErrorIs(t, err, error1)
+	assert.ErrorIs(t, err, error2)
+	assert.Equal(t, 0, count)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldNotExceedConcurrency(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	updates := make([]model.ExchangeRateUpdateDbo, 0, 6)
+	for i, from := range []string{"EUR", "MXN", "GBP", "JPY", "CHF", "CAD"} {
+		updates = append(updates, model.ExchangeRateUpdateDbo{Id: fmt.Sprintf("update-id-%d", i), FromCurrency: from, ToCurrency: "USD"})
+	}
+
+	var running, maxRunning atomic.Int32
+	rate := newQuote(1.5)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return(updates, nil)
+	mockClient.On("GetRates", mock.Anything, []string{"USD"}).Run(func(mock.Arguments) {
+		current := running.Add(1)
+		for {
+			observed := maxRunning.Load()
+			if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+	}).Return(map[string]model.RateQuote{"USD": rate}, nil)
+	mockRepo.On("UpdateRate", mock.Anything, "worker-1", mock.Anything, "USD", rate).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldFetchRatesOncePerFromCurrency(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "EUR", ToCurrency: "MXN"}
+	update3 := model.ExchangeRateUpdateDbo{Id: "update-id-3", FromCurrency: "USD", ToCurrency: "MXN"}
+
+	usdEur, usdMxn, eurMxn := newQuote(0.85), newQuote(17.5), newQuote(20.5)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2, update3}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR", "MXN"}).Return(map[string]model.RateQuote{"EUR": usdEur, "MXN": usdMxn}, nil).Once()
+	mockClient.On("GetRates", "EUR", []string{"MXN"}).Return(map[string]model.RateQuote{"MXN": eurMxn}, nil).Once()
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", "USD", "EUR", usdEur).Return(nil)
+	mockRepo.On("UpdateRate", update2.Id, "worker-1", "EUR", "MXN", eurMxn).Return(nil)
+	mockRepo.On("UpdateRate", update3.Id, "worker-1", "USD", "MXN", usdMxn).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldSetErrorWhenRateUnsupportedByAllProviders(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "USD", ToCurrency: "MXN"}
+
+	rate := newQuote(0.85)
+	unsupported := &integration.ProviderError{Provider: "currency-api", Message: "rate USD/MXN not found", Err: integration.ErrSymbolUnsupported}
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR", "MXN"}).
+		Return(map[string]model.RateQuote{"EUR": rate}, &integration.PartialRatesError{Errors: map[string]error{"MXN": unsupported}})
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", "USD", "EUR", rate).Return(nil)
+	mockRepo.On("SetUpdateError", update2.Id, "worker-1", "currency-api: rate USD/MXN not found").Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertNotCalled(t, "ScheduleUpdateRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldRetryMissingRateWhenOtherProviderFailed(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "USD", ToCurrency: "MXN"}
+
+	rate := newQuote(0.85)
+	missing := errors.Join(
+		&integration.ProviderError{Provider: "ecb", Message: "rate USD/MXN not found", Err: integration.ErrSymbolUnsupported},
+		fmt.Errorf("currency-api: %w", integration.ErrCircuitOpen),
+	)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR", "MXN"}).
+		Return(map[string]model.RateQuote{"EUR": rate}, &integration.PartialRatesError{Errors: map[string]error{"MXN": missing}})
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", "USD", "EUR", rate).Return(nil)
+	mockRepo.On("ScheduleUpdateRetry", update2.Id, "worker-1", missing.Error(), mock.Anything).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertNotCalled(t, "SetUpdateError", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestExecuteUpdate_ShouldKeepQuotesInErrorWhenConsensusNotReached(t *testing.T) {
+	mockRepo, mockClient, worker := createMocks()
+
+	update1 := model.ExchangeRateUpdateDbo{Id: "update-id-1", FromCurrency: "USD", ToCurrency: "EUR"}
+	update2 := model.ExchangeRateUpdateDbo{Id: "update-id-2", FromCurrency: "USD", ToCurrency: "MXN"}
+
+	rate := newQuote(0.85)
+	consensusError := &integration.ConsensusError{
+		Base:   "USD",
+		Target: "MXN",
+		Quotes: []model.ProviderQuote{
+			{Provider: "first", Rate: decimal.RequireFromString("17.1")},
+			{Provider: "second", Rate: decimal.RequireFromString("18.9"), Rejected: true},
+		},
+		MinQuotes: 2,
+	}
+	partial := &integration.PartialRatesError{Errors: map[string]error{"MXN": consensusError}}
+
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2}, nil)
+	mockClient.On("GetRates", "USD", []string{"EUR", "MXN"}).Return(map[string]model.RateQuote{"EUR": rate}, partial)
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", "USD", "EUR", rate).Return(nil)
+	mockRepo.On("ScheduleUpdateRetry", update2.Id, "worker-1",
+		"consensus rate USD/MXN not reached, 1 of 2 required quotes accepted: first 17.1, second 18.9 rejected",
+		mock.AnythingOfType("time.Time")).Return(nil)
+
+	count, err := worker.ExecuteUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRun_ShouldExecuteUpdateOnNotification(t *testing.T) {
+	mockRepo, _, worker := createMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+	notifications := make(chan struct{}, 1)
+
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return([]model.ExchangeRateUpdateDbo{}, nil)
+
+	notifications <- struct{}{}
+	worker.Run(ctx, context.Background(), nil, notifications)
+
+	mockRepo.AssertNumberOfCalls(t, "GetRatesForUpdate", 1)
+}
+
+func TestRun_ShouldDrainQueuedNotifications(t *testing.T) {
+	mockRepo, _, worker := createMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+	notifications := make(chan struct{}, 3)
+
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).
+		Run(func(args mock.Arguments) {
+			assert.Empty(t, notifications)
+			cancel()
+		}).
+		Return([]model.ExchangeRateUpdateDbo{}, nil)
+
+	for range 3 {
+		notifications <- struct{}{}
+	}
+	worker.Run(ctx, context.Background(), nil, notifications)
+
+	mockRepo.AssertNumberOfCalls(t, "GetRatesForUpdate", 1)
+}
+
+func TestRun_ShouldExecuteUpdateOnTickWithoutNotifications(t *testing.T) {
+	mockRepo, _, worker := createMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time, 1)
+
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return([]model.ExchangeRateUpdateDbo{}, nil)
+
+	ticks <- time.Now()
+	worker.Run(ctx, context.Background(), ticks, make(chan struct{}))
+
+	mockRepo.AssertNumberOfCalls(t, "GetRatesForUpdate", 1)
+}
+
+func TestRun_ShouldReturnWhenContextDone(t *testing.T) {
+	mockRepo, _, worker := createMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	worker.Run(ctx, context.Background(), make(chan time.Time), make(chan struct{}))
+
+	mockRepo.AssertNotCalled(t, "GetRatesForUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReclaimStuckUpdates_ShouldUseConfiguredLimits(t *testing.T) {
+	mockRepo, _, worker := createMocks()
+
+	expected := model.ReclaimResult{Requeued: 2, Failed: 1}
+	mockRepo.On("ReclaimStuckUpdates", 3, time.Hour).Return(expected, nil)
+
+	result, err := worker.ReclaimStuckUpdates(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRetryDelay_ShouldGrowExponentiallyUpToMaxDelay(t *testing.T) {
+	_, _, worker := createMocks()
+
+	for attempts, expected := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		10: 10 * time.Second,
+		64: 10 * time.Second,
+	} {
+		delay := worker.retryDelay(attempts)
+		assert.GreaterOrEqual(t, delay, expected/2)
+		assert.LessOrEqual(t, delay, expected)
+	}
+}
+
 func TestExecuteUpdate_ShouldReturnErrorWhenWeHaveProblemWithRepository(t *testing.T) {
 	mockRepo, mockClient, worker := createMocks()
 
@@ -53,23 +474,23 @@ func TestExecuteUpdate_ShouldReturnErrorWhenWeHaveProblemWithRepository(t *testi
 		Status:       model.StatusUpdating,
 	}
 
-	rate := decimal.NewFromFloat(1.18)
+	rate := newQuote(1.18)
 	repositoryError := errors.New("database error")
 
-	mockRepo.On("GetRatesForUpdate", 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
-	mockClient.On("GetRate", rateUpdate.FromCurrency, rateUpdate.ToCurrency).Return(rate, nil)
-	mockRepo.On("UpdateRate", rateUpdate.Id, rateUpdate.FromCurrency, rateUpdate.ToCurrency, rate).Return(repositoryError)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{rateUpdate}, nil)
+	mockClient.On("GetRates", rateUpdate.FromCurrency, []string{rateUpdate.ToCurrency}).Return(map[string]model.RateQuote{rateUpdate.ToCurrency: rate}, nil)
+	mockRepo.On("UpdateRate", rateUpdate.Id, "worker-1", rateUpdate.FromCurrency, rateUpdate.ToCurrency, rate).Return(repositoryError)
 
-	count, err := worker.ExecuteUpdate()
+	count, err := worker.ExecuteUpdate(context.Background())
 
 	assert.Error(t, err)
-	assert.Equal(t, repositoryError, err)
+	assert.ErrorIs(t, err, repositoryError)
 	assert.Equal(t, 0, count)
 	mockRepo.AssertExpectations(t)
 	mockClient.AssertExpectations(t)
 }
 
-func TestExecuteUpdate_ShouldReturnUpdateCountBeforeError(t *testing.T) {
+func TestExecuteUpdate_ShouldReturnUpdateCountWithError(t *testing.T) {
 	mockRepo, mockClient, worker := createMocks()
 
 	update1 := model.ExchangeRateUpdateDbo{
@@ -93,25 +514,25 @@ func TestExecuteUpdate_ShouldReturnUpdateCountBeforeError(t *testing.T) {
 		Status:       model.StatusUpdating,
 	}
 
-	mockRepo.On("GetRatesForUpdate", 10).Return([]model.ExchangeRateUpdateDbo{update1, update2, update3}, nil)
+	mockRepo.On("GetRatesForUpdate", "worker-1", 30*time.Second, 10).Return([]model.ExchangeRateUpdateDbo{update1, update2, update3}, nil)
 
-	rate1 := decimal.NewFromFloat(1.18)
-	mockClient.On("GetRate", update1.FromCurrency, update1.ToCurrency).Return(rate1, nil)
-	mockRepo.On("UpdateRate", update1.Id, update1.FromCurrency, update1.ToCurrency, rate1).Return(nil)
+	rate1 := newQuote(1.18)
+	mockClient.On("GetRates", update1.FromCurrency, []string{update1.ToCurrency}).Return(map[string]model.RateQuote{update1.ToCurrency: rate1}, nil)
+	mockRepo.On("UpdateRate", update1.Id, "worker-1", update1.FromCurrency, update1.ToCurrency, rate1).Return(nil)
 
-	rate2 := decimal.NewFromFloat(1.35)
-	mockClient.On("GetRate", update2.FromCurrency, update2.ToCurrency).Return(rate2, nil)
-	mockRepo.On("UpdateRate", update2.Id, update2.FromCurrency, update2.ToCurrency, rate2).Return(nil)
+	rate2 := newQuote(1.35)
+	mockClient.On("GetRates", update2.FromCurrency, []string{update2.ToCurrency}).Return(map[string]model.RateQuote{update2.ToCurrency: rate2}, nil)
+	mockRepo.On("UpdateRate", update2.Id, "worker-1", update2.FromCurrency, update2.ToCurrency, rate2).Return(nil)
 
-	rate3 := decimal.NewFromFloat(155.23)
+	rate3 := newQuote(155.23)
 	repositoryError := errors.New("database error")
-	mockClient.On("GetRate", update3.FromCurrency, update3.ToCurrency).Return(rate3, nil)
-	mockRepo.On("UpdateRate", update3.Id, update3.FromCurrency, update3.ToCurrency, rate3).Return(repositoryError)
+	mockClient.On("GetRates", update3.FromCurrency, []string{update3.ToCurrency}).Return(map[string]model.RateQuote{update3.ToCurrency: rate3}, nil)
+	mockRepo.On("UpdateRate", update3.Id, "worker-1", update3.FromCurrency, update3.ToCurrency, rate3).Return(repositoryError)
 
-	count, err := worker.ExecuteUpdate()
+	count, err := worker.ExecuteUpdate(context.Background())
 
 	assert.Error(t, err)
-	assert.Equal(t, repositoryError, err)
+	assert.ErrorIs(t, err, repositoryError)
 	assert.Equal(t, 2, count)
 	mockRepo.AssertExpectations(t)
 	mockClient.AssertExpectations(t)
@@ -120,7 +541,18 @@ func TestExecuteUpdate_ShouldReturnUpdateCountBeforeError(t *testing.T) {
 func createMocks() (*mockRepository, *mockApiClient, *RateServiceWorker) {
 	mockRepo := new(mockRepository)
 	mockClient := new(mockApiClient)
-	config := &config.Config{WorkerFetchSize: 10}
+	config := &config.Config{
+		WorkerFetchSize:      10,
+		WorkerMaxAttempts:    3,
+		WorkerRetryBaseDelay: time.Second,
+		WorkerRetryMaxDelay:  10 * time.Second,
+		WorkerId:             "worker-1",
+		WorkerLeaseDuration:  30 * time.Second,
+		WorkerMaxUpdateAge:   time.Hour,
+		WorkerConcurrency:    2,
+		BreakerCoolDown:      time.Minute,
+		RateProviders:        []string{"currency-api"},
+	}
 	worker := &RateServiceWorker{
 		config:     config,
 		repository: mockRepo,
@@ -130,3 +562,7 @@ func createMocks() (*mockRepository, *mockApiClient, *RateServiceWorker) {
 	return mockRepo, mockClient, worker
 
 }
+
+func newQuote(rate float64) model.RateQuote {
+	return model.RateQuote{Rate: decimal.NewFromFloat(rate), Provider: "currency-api"}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"exchange-rates-service/src/internal"
+	"exchange-rates-service/src/internal/model"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateGraph holds stored rates in both directions, so a missing pair can be built from the available legs
+type rateGraph map[string][]model.ExchangeRateLeg
+
+func newRateGraph(rates []model.ExchangeRateDbo, inversePrecision int32) rateGraph {
+	graph := rateGraph{}
+	for _, rate := range rates {
+		if rate.RateValue == nil || rate.UpdateTime == nil || rate.RateValue.IsZero() {
+			continue
+		}
+
+		provider := ""
+		if rate.Provider != nil {
+			provider = *rate.Provider
+		}
+
+		graph[rate.FromCurrency] = append(graph[rate.FromCurrency], model.ExchangeRateLeg{
+			FromCurrency:   rate.FromCurrency,
+			ToCurrency:     rate.ToCurrency,
+			Rate:           *rate.RateValue,
+			UpdateDateTime: *rate.UpdateTime,
+			Provider:       provider,
+		})
+
+		graph[rate.ToCurrency] = append(graph[rate.ToCurrency], model.ExchangeRateLeg{
+			FromCurrency:   rate.ToCurrency,
+			ToCurrency:     rate.FromCurrency,
+			Rate:           invertRate(*rate.RateValue, inversePrecision),
+			UpdateDateTime: *rate.UpdateTime,
+			Provider:       provider,
+			Inverted:       true,
+		})
+	}
+
+	// Stored directions go first, so they win over inverted legs of the same length
+	for _, legs := range graph {
+		sort.SliceStable(legs, func(i, j int) bool {
+			if legs[i].Inverted != legs[j].Inverted {
+				return !legs[i].Inverted
+			}
+			return legs[i].ToCurrency < legs[j].ToCurrency
+		})
+	}
+
+	return graph
+}
+
+func (graph rateGraph) leg(from string, to string) (model.ExchangeRateLeg, bool) {
+	for _, leg := range graph[from] {
+		if leg.ToCurrency == to {
+			return leg, true
+		}
+	}
+	return model.ExchangeRateLeg{}, false
+}
+
+func (graph rateGraph) pivotPath(from string, to string, pivot string) ([]model.ExchangeRateLeg, bool) {
+	if pivot == "" || pivot == from || pivot == to {
+		return nil, false
+	}
+
+	first, ok := graph.leg(from, pivot)
+	if !ok {
+		return nil, false
+	}
+
+	second, ok := graph.leg(pivot, to)
+	if !ok {
+		return nil, false
+	}
+
+	return []model.ExchangeRateLeg{first, second}, true
+}
+
+// shortestPath finds the path with the least number of legs using breadth-first search
+func (graph rateGraph) shortestPath(from string, to string) ([]model.ExchangeRateLeg, bool) {
+	previous := map[string]model.ExchangeRateLeg{}
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		currency := queue[0]
+		queue = queue[1:]
+
+		for _, leg := range graph[currency] {
+			if visited[leg.ToCurrency] {
+				continue
+			}
+			visited[leg.ToCurrency] = true
+			previous[leg.ToCurrency] = leg
+
+			if leg.ToCurrency == to {
+				path := make([]model.ExchangeRateLeg, 0)
+				for current := to; current != from; current = previous[current].FromCurrency {
+					path = append([]model.ExchangeRateLeg{previous[current]}, path...)
+				}
+				return path, true
+			}
+
+			queue = append(queue, leg.ToCurrency)
+		}
+	}
+
+	return nil, false
+}
+
+// triangulate builds the rate from the stored legs through the pivot currency from config.
+// Falls back to the shortest path across all stored pairs. UpdateDateTime is the oldest leg time
+func (service *RateService) triangulate(ctx context.Context, from string, to string) (model.ExchangeRate, error) {
+	rates, err := service.repository.GetAllRates(ctx)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+
+	graph := newRateGraph(rates, service.config.InverseRatePrecision)
+
+	path, ok := graph.pivotPath(from, to, service.config.TriangulationPivot)
+	if !ok {
+		path, ok = graph.shortestPath(from, to)
+	}
+
+	if !ok {
+		return model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found")
+	}
+
+	rate := decimal.NewFromInt(1)
+	updateTime := path[0].UpdateDateTime
+	for _, leg := range path {
+		rate = rate.Mul(leg.Rate)
+		if leg.UpdateDateTime.Before(updateTime) {
+			updateTime = leg.UpdateDateTime
+		}
+	}
+
+	return model.ExchangeRate{
+		Rate:           &rate,
+		UpdateDateTime: &updateTime,
+		Derived:        model.DerivationCross,
+		Legs:           path,
+	}, nil
+}
+
+func invertRate(rate decimal.Decimal, precision int32) decimal.Decimal {
+	return decimal.NewFromInt(1).DivRound(rate, precision)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"exchange-rates-service/src/internal"
+	"exchange-rates-service/src/internal/model"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLastRate_ShouldTriangulateThroughPivot(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+	service.config.TriangulationPivot = "EUR"
+
+	olderTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	newerTime := olderTime.Add(time.Hour)
+	eurUsd, eurMxn := decimal.RequireFromString("1.25"), decimal.RequireFromString("20")
+
+	mockRepo.On("GetLastRate", "USD", "MXN").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetLastRate", "MXN", "USD").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetAllRates").Return([]model.ExchangeRateDbo{
+		{FromCurrency: "EUR", ToCurrency: "USD", RateValue: &eurUsd, UpdateTime: &olderTime},
+		{FromCurrency: "EUR", ToCurrency: "MXN", RateValue: &eurMxn, UpdateTime: &newerTime},
+	}, nil)
+
+	rate, err := service.GetLastRate(context.Background(), "USD", "MXN", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "16", rate.Rate.String())
+	assert.Equal(t, &olderTime, rate.UpdateDateTime)
+	assert.Equal(t, model.DerivationCross, rate.Derived)
+	assert.Len(t, rate.Legs, 2)
+	assert.Equal(t, "USD", rate.Legs[0].FromCurrency)
+	assert.Equal(t, "EUR", rate.Legs[0].ToCurrency)
+	assert.True(t, rate.Legs[0].Inverted)
+	assert.Equal(t, "EUR", rate.Legs[1].FromCurrency)
+	assert.Equal(t, "MXN", rate.Legs[1].ToCurrency)
+	assert.False(t, rate.Legs[1].Inverted)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetLastRate_ShouldUseShortestPathWithoutPivot(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	updateTime := time.Now().UTC()
+	usdEur, eurMxn := decimal.RequireFromString("0.8"), decimal.RequireFromString("20")
+
+	mockRepo.On("GetLastRate", "USD", "MXN").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetLastRate", "MXN", "USD").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetAllRates").Return([]model.ExchangeRateDbo{
+		{FromCurrency: "USD", ToCurrency: "EUR", RateValue: &usdEur, UpdateTime: &updateTime},
+		{FromCurrency: "EUR", ToCurrency: "MXN", RateValue: &eurMxn, UpdateTime: &updateTime},
+	}, nil)
+
+	rate, err := service.GetLastRate(context.Background(), "USD", "MXN", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "16", rate.Rate.String())
+	assert.Len(t, rate.Legs, 2)
+	assert.False(t, rate.Legs[0].Inverted)
+	assert.False(t, rate.Legs[1].Inverted)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetLastRate_ReturnsNotFoundWhenNoPathExists(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	updateTime := time.Now().UTC()
+	eurUsd := decimal.RequireFromString("1.25")
+
+	mockRepo.On("GetLastRate", "USD", "MXN").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetLastRate", "MXN", "USD").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetAllRates").Return([]model.ExchangeRateDbo{
+		{FromCurrency: "EUR", ToCurrency: "USD", RateValue: &eurUsd, UpdateTime: &updateTime},
+	}, nil)
+
+	_, err := service.GetLastRate(context.Background(), "USD", "MXN", true)
+
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.NotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetLastRate_ShouldNotTriangulateWhenDirectRateExists(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+	service.config.TriangulationPivot = "EUR"
+
+	updateTime := time.Now().UTC()
+	rateValue := decimal.RequireFromString("17.5")
+	direct := model.ExchangeRate{Rate: &rateValue, UpdateDateTime: &updateTime}
+
+	mockRepo.On("GetLastRate", "USD", "MXN").Return(direct, nil)
+
+	rate, err := service.GetLastRate(context.Background(), "USD", "MXN", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, direct, rate)
+	mockRepo.AssertNotCalled(t, "GetAllRates")
+}
+
+func TestGetLastRate_ShouldInvertOppositeDirection(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	updateTime := time.Now().UTC()
+	usdEur := decimal.RequireFromString("0.9")
+
+	mockRepo.On("GetLastRate", "EUR", "USD").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+	mockRepo.On("GetLastRate", "USD", "EUR").Return(model.ExchangeRate{Rate: &usdEur, UpdateDateTime: &updateTime}, nil)
+
+	rate, err := service.GetLastRate(context.Background(), "EUR", "USD", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.111111", rate.Rate.String())
+	assert.Equal(t, &updateTime, rate.UpdateDateTime)
+	assert.Equal(t, model.DerivationInverse, rate.Derived)
+	assert.Len(t, rate.Legs, 1)
+	assert.Equal(t, "EUR", rate.Legs[0].FromCurrency)
+	assert.Equal(t, "USD", rate.Legs[0].ToCurrency)
+	assert.True(t, rate.Legs[0].Inverted)
+	mockRepo.AssertNotCalled(t, "GetAllRates")
+}
+
+func TestGetLastRate_ShouldNotDeriveWhenDisallowed(t *testing.T) {
+	mockRepo, service := createMockServiceWithRepository()
+
+	mockRepo.On("GetLastRate", "EUR", "USD").Return(model.ExchangeRate{}, internal.NewNotFoundError("rate updates not found"))
+
+	_, err := service.GetLastRate(context.Background(), "EUR", "USD", false)
+
+	assert.Error(t, err)
+	assert.Equal(t, err.(*internal.ServiceError).ErrorType, internal.NotFound)
+	mockRepo.AssertNotCalled(t, "GetLastRate", "USD", "EUR")
+	mockRepo.AssertNotCalled(t, "GetAllRates")
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"exchange-rates-service/src/internal"
+	"exchange-rates-service/src/internal/model"
+	"fmt"
+)
+
+type PostgresCurrencyStorage struct {
+	db *sql.DB
+}
+
+type CurrencyStorage interface {
+	GetCurrencies(ctx context.Context) ([]model.Currency, error)
+	SetActive(ctx context.Context, code string, active bool) (*model.Currency, error)
+}
+
+func NewCurrencyStorage(db *sql.DB) CurrencyStorage {
+	return &PostgresCurrencyStorage{db: db}
+}
+
+const getCurrenciesSql = `
+SELECT code, numeric_code, minor_units, name, active FROM currency
+ORDER BY code
+`
+
+func (storage *PostgresCurrencyStorage) GetCurrencies(ctx context.Context) ([]model.Currency, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getCurrenciesSql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	currencies := make([]model.Currency, 0)
+	for rows.Next() {
+		currency := model.Currency{}
+		if err := rows.Scan(&currency.Code, &currency.NumericCode, &currency.MinorUnits, &currency.Name, &currency.Active); err != nil {
+			return nil, err
+		}
+
+		currencies = append(currencies, currency)
+	}
+
+	return currencies, rows.Err()
+}
+
+const setCurrencyActiveSql = `
+UPDATE currency
+SET active = $2
+WHERE code = $1
+RETURNING numeric_code, minor_units, name
+`
+
+func (storage *PostgresCurrencyStorage) SetActive(ctx context.Context, code string, active bool) (*model.Currency, error) {
+	stmt, err := storage.db.PrepareContext(ctx, setCurrencyActiveSql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, code, active)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hasData := rows.Next()
+	if !hasData {
+		return nil, internal.NewNotFoundError(fmt.Sprintf("currency %s not found", code))
+	}
+
+	currency := model.Currency{
+		Code:   code,
+		Active: active,
+	}
+	err = rows.Scan(&currency.NumericCode, &currency.MinorUnits, &currency.Name)
+	return &currency, err
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"exchange-rates-service/src/internal/model"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrencies_Success(t *testing.T) {
+	storage, _, mock := createCurrencyMockStorage(t)
+
+	rows := sqlmock.NewRows([]string{"code", "numeric_code", "minor_units", "name", "active"}).
+		AddRow("EUR", "978", 2, "Euro", true).
+		AddRow("JPY", "392", 0, "Yen", false)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(getCurrenciesSql)).
+		ExpectQuery().
+		WillReturnRows(rows)
+
+	currencies, err := storage.GetCurrencies(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Currency{
+		{Code: "EUR", NumericCode: "978", MinorUnits: 2, Name: "Euro", Active: true},
+		{Code: "JPY", NumericCode: "392", MinorUnits: 0, Name: "Yen", Active: false},
+	}, currencies)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetActive_Success(t *testing.T) {
+	storage, _, mock := createCurrencyMockStorage(t)
+
+	rows := sqlmock.NewRows([]string{"numeric_code", "minor_units", "name"}).
+		AddRow("826", 2, "Pound Sterling")
+
+	mock.ExpectPrepare(regexp.QuoteMeta(setCurrencyActiveSql)).
+		ExpectQuery().
+		WithArgs("GBP", true).
+		WillReturnRows(rows)
+
+	currency, err := storage.SetActive(context.Background(), "GBP", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Currency{Code: "GBP", NumericCode: "826", MinorUnits: 2, Name: "Pound Sterling", Active: true}, currency)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetActive_NotFound(t *testing.T) {
+	storage, _, mock := createCurrencyMockStorage(t)
+
+	rows := sqlmock.NewRows([]string{"numeric_code", "minor_units", "name"})
+
+	mock.ExpectPrepare(regexp.QuoteMeta(setCurrencyActiveSql)).
+		ExpectQuery().
+		WithArgs("XXX", false).
+		WillReturnRows(rows)
+
+	currency, err := storage.SetActive(context.Background(), "XXX", false)
+
+	assert.Nil(t, currency)
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func createCurrencyMockStorage(t *testing.T) (CurrencyStorage, *sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	storage := NewCurrencyStorage(db)
+	return storage, db, mock
+}
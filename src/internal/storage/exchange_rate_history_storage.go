@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"exchange-rates-service/src/internal/model"
+	"time"
+)
+
+type PostgresHistoryStorage struct {
+	db *sql.DB
+}
+
+type HistoryStorage interface {
+	AddRateTx(ctx context.Context, tx *sql.Tx, model *model.ExchangeRateHistoryDbo) error
+	GetRateHistory(
+		ctx context.Context,
+		from string,
+		to string,
+		since *time.Time,
+		until *time.Time,
+		after *model.RateHistoryCursor,
+		limit int) ([]model.ExchangeRateHistoryDbo, error)
+}
+
+func NewHistoryStorage(db *sql.DB) HistoryStorage {
+	return &PostgresHistoryStorage{db: db}
+}
+
+const addRateSql = `
+INSERT INTO exchange_rate_history(update_id, from_currency, to_currency, rate_value, update_time, provider)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+func (storage *PostgresHistoryStorage) AddRateTx(ctx context.Context, tx *sql.Tx, model *model.ExchangeRateHistoryDbo) error {
+	stmt, err := tx.PrepareContext(ctx, addRateSql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, model.UpdateId, model.FromCurrency, model.ToCurrency, model.RateValue, model.UpdateTime, model.Provider)
+	return err
+}
+
+const getRateHistorySql = `
+SELECT id, update_id, rate_value, update_time, provider FROM exchange_rate_history
+WHERE from_currency = $1 AND to_currency = $2
+	AND ($3::timestamp IS NULL OR update_time >= $3)
+	AND ($4::timestamp IS NULL OR update_time < $4)
+	AND ($6::bigint IS NULL OR (update_time, id) > ($5, $6))
+ORDER BY update_time, id
+LIMIT $7
+`
+
+func (storage *PostgresHistoryStorage) GetRateHistory(
+	ctx context.Context,
+	from string,
+	to string,
+	since *time.Time,
+	until *time.Time,
+	after *model.RateHistoryCursor,
+	limit int) ([]model.ExchangeRateHistoryDbo, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getRateHistorySql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var afterTime *time.Time
+	var afterId *int64
+	if after != nil {
+		afterTime = &after.UpdateTime
+		afterId = &after.Id
+	}
+
+	rows, err := stmt.QueryContext(ctx, from, to, since, until, afterTime, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dbos := make([]model.ExchangeRateHistoryDbo, 0, limit)
+	for rows.Next() {
+		rate := model.ExchangeRateHistoryDbo{
+			FromCurrency: from,
+			ToCurrency:   to,
+		}
+
+		if err := rows.Scan(&rate.Id, &rate.UpdateId, &rate.RateValue, &rate.UpdateTime, &rate.Provider); err != nil {
+			return nil, err
+		}
+
+		dbos = append(dbos, rate)
+	}
+
+	return dbos, rows.Err()
+}
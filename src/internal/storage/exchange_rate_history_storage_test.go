@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"exchange-rates-service/src/internal/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRateTx_Success(t *testing.T) {
+	storage, db, mock := createHistoryMockStorage(t)
+	rateValue, updateTime, provider := decimal.NewFromFloat(1.2345), time.Now(), "currency-api"
+
+	dbo := model.ExchangeRateHistoryDbo{
+		UpdateId:     "test-update-id",
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		RateValue:    &rateValue,
+		UpdateTime:   &updateTime,
+		Provider:     &provider,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta(addRateSql)).
+		ExpectExec().
+		WithArgs(dbo.UpdateId, dbo.FromCurrency, dbo.ToCurrency, dbo.RateValue, dbo.UpdateTime, provider).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	err = storage.AddRateTx(context.Background(), tx, &dbo)
+	require.NoError(t, err)
+
+	err = tx.Commit()
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRateHistory_Success(t *testing.T) {
+	storage, _, mock := createHistoryMockStorage(t)
+
+	from, to := "USD", "EUR"
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	firstTime, secondTime := since.Add(time.Hour), since.Add(2*time.Hour)
+	firstRate, secondRate := decimal.NewFromFloat(1.1), decimal.NewFromFloat(1.2)
+
+	provider := "currency-api"
+	rows := sqlmock.NewRows([]string{"id", "update_id", "rate_value", "update_time", "provider"}).
+		AddRow(1, "update-1", firstRate, firstTime, provider).
+		AddRow(2, "update-2", secondRate, secondTime, nil)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(getRateHistorySql)).
+		ExpectQuery().
+		WithArgs(from, to, &since, nil, nil, nil, 10).
+		WillReturnRows(rows)
+
+	history, err := storage.GetRateHistory(context.Background(), from, to, &since, nil, nil, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, model.ExchangeRateHistoryDbo{
+		Id:           1,
+		UpdateId:     "update-1",
+		FromCurrency: from,
+		ToCurrency:   to,
+		RateValue:    &firstRate,
+		UpdateTime:   &firstTime,
+		Provider:     &provider,
+	}, history[0])
+	assert.Equal(t, int64(2), history[1].Id)
+	assert.Equal(t, &secondRate, history[1].RateValue)
+	assert.Nil(t, history[1].Provider)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRateHistory_ShouldPassCursor(t *testing.T) {
+	storage, _, mock := createHistoryMockStorage(t)
+
+	from, to := "USD", "EUR"
+	cursor := model.RateHistoryCursor{UpdateTime: time.Now().UTC(), Id: 42}
+	rows := sqlmock.NewRows([]string{"id", "update_id", "rate_value", "update_time", "provider"})
+
+	mock.ExpectPrepare(regexp.QuoteMeta(getRateHistorySql)).
+		ExpectQuery().
+		WithArgs(from, to, nil, nil, &cursor.UpdateTime, &cursor.Id, 10).
+		WillReturnRows(rows)
+
+	history, err := storage.GetRateHistory(context.Background(), from, to, nil, nil, &cursor, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, history, 0)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func createHistoryMockStorage(t *testing.T) (HistoryStorage, *sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	storage := NewHistoryStorage(db)
+	return storage, db, mock
+}
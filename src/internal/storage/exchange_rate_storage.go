@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/model"
+
+	"github.com/lib/pq"
 )
 
 type PostgresRateStorage struct {
@@ -12,8 +14,10 @@ type PostgresRateStorage struct {
 }
 
 type RateStorage interface {
-	GetRate(from string, to string) (*model.ExchangeRateDbo, error)
-	SetRateTx(tx *sql.Tx, model *model.ExchangeRateDbo) error
+	GetRate(ctx context.Context, from string, to string) (*model.ExchangeRateDbo, error)
+	GetRates(ctx context.Context, pairs []model.CurrencyPair) ([]model.ExchangeRateDbo, error)
+	GetAllRates(ctx context.Context) ([]model.ExchangeRateDbo, error)
+	SetRateTx(ctx context.Context, tx *sql.Tx, model *model.ExchangeRateDbo) error
 }
 
 func NewRateStorage(db *sql.DB) RateStorage {
@@ -21,18 +25,18 @@ func NewRateStorage(db *sql.DB) RateStorage {
 }
 
 const getRateSql = `
-SELECT rate_value, update_time FROM exchange_rate
+SELECT rate_value, update_time, provider FROM exchange_rate
 WHERE from_currency = $1 AND to_currency = $2
 `
 
-func (storage *PostgresRateStorage) GetRate(from string, to string) (*model.ExchangeRateDbo, error) {
-	stmt, err := storage.db.PrepareContext(context.Background(), getRateSql)
+func (storage *PostgresRateStorage) GetRate(ctx context.Context, from string, to string) (*model.ExchangeRateDbo, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getRateSql)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.Background(), from, to)
+	rows, err := stmt.QueryContext(ctx, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -47,24 +51,88 @@ func (storage *PostgresRateStorage) GetRate(from string, to string) (*model.Exch
 		FromCurrency: from,
 		ToCurrency:   to,
 	}
-	err = rows.Scan(&rate.RateValue, &rate.UpdateTime)
+	err = rows.Scan(&rate.RateValue, &rate.UpdateTime, &rate.Provider)
 	return &rate, err
 }
 
+const getRatesSql = `
+SELECT exchange_rate.from_currency, exchange_rate.to_currency, rate_value, update_time, provider FROM exchange_rate
+JOIN unnest($1::text[], $2::text[]) AS pair(from_currency, to_currency)
+	ON exchange_rate.from_currency = pair.from_currency AND exchange_rate.to_currency = pair.to_currency
+`
+
+// GetRates returns stored rates of the pairs in one query. Pairs without a stored rate are omitted
+func (storage *PostgresRateStorage) GetRates(ctx context.Context, pairs []model.CurrencyPair) ([]model.ExchangeRateDbo, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getRatesSql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	fromCurrencies := make([]string, 0, len(pairs))
+	toCurrencies := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		fromCurrencies = append(fromCurrencies, pair.From)
+		toCurrencies = append(toCurrencies, pair.To)
+	}
+
+	rows, err := stmt.QueryContext(ctx, pq.Array(fromCurrencies), pq.Array(toCurrencies))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRates(rows)
+}
+
+const getAllRatesSql = `
+SELECT from_currency, to_currency, rate_value, update_time, provider FROM exchange_rate
+`
+
+func (storage *PostgresRateStorage) GetAllRates(ctx context.Context) ([]model.ExchangeRateDbo, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getAllRatesSql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRates(rows)
+}
+
+func scanRates(rows *sql.Rows) ([]model.ExchangeRateDbo, error) {
+	dbos := make([]model.ExchangeRateDbo, 0)
+	for rows.Next() {
+		rate := model.ExchangeRateDbo{}
+		if err := rows.Scan(&rate.FromCurrency, &rate.ToCurrency, &rate.RateValue, &rate.UpdateTime, &rate.Provider); err != nil {
+			return nil, err
+		}
+
+		dbos = append(dbos, rate)
+	}
+
+	return dbos, rows.Err()
+}
+
 const setRateSql = `
-INSERT INTO exchange_rate(from_currency, to_currency, rate_value, update_time)
-VALUES ($1, $2, $3, $4) 
+INSERT INTO exchange_rate(from_currency, to_currency, rate_value, update_time, provider)
+VALUES ($1, $2, $3, $4, $5) 
 ON CONFLICT(from_currency, to_currency) 
-DO UPDATE SET rate_value = $3, update_time = $4
+DO UPDATE SET rate_value = $3, update_time = $4, provider = $5
 `
 
-func (storage *PostgresRateStorage) SetRateTx(tx *sql.Tx, model *model.ExchangeRateDbo) error {
-	stmt, err := tx.PrepareContext(context.Background(), setRateSql)
+func (storage *PostgresRateStorage) SetRateTx(ctx context.Context, tx *sql.Tx, model *model.ExchangeRateDbo) error {
+	stmt, err := tx.PrepareContext(ctx, setRateSql)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(context.Background(), model.FromCurrency, model.ToCurrency, model.RateValue, model.UpdateTime)
+	_, err = stmt.ExecContext(ctx, model.FromCurrency, model.ToCurrency, model.RateValue, model.UpdateTime, model.Provider)
 	return err
 }
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"exchange-rates-service/src/internal/model"
 	"regexp"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,15 +18,15 @@ import (
 func TestGetRate_Success(t *testing.T) {
 	storage, _, mock := createRateMockStorage(t)
 
-	from, to, rateValue, updateTime := "USD", "EUR", "12345", time.Now()
-	rows := sqlmock.NewRows([]string{"rate_value", "update_time"}).AddRow(rateValue, updateTime)
+	from, to, rateValue, updateTime, provider := "USD", "EUR", "12345", time.Now(), "currency-api"
+	rows := sqlmock.NewRows([]string{"rate_value", "update_time", "provider"}).AddRow(rateValue, updateTime, provider)
 
 	mock.ExpectPrepare(regexp.QuoteMeta(getRateSql)).
 		ExpectQuery().
 		WithArgs(from, to).
 		WillReturnRows(rows)
 
-	rate, err := storage.GetRate(from, to)
+	rate, err := storage.GetRate(context.Background(), from, to)
 
 	assert.NoError(t, err)
 	assert.Equal(t, from, rate.FromCurrency)
@@ -33,6 +35,7 @@ func TestGetRate_Success(t *testing.T) {
 	expectedRateValue, _ := decimal.NewFromString(rateValue)
 	assert.Equal(t, &expectedRateValue, rate.RateValue)
 	assert.Equal(t, &updateTime, rate.UpdateTime)
+	assert.Equal(t, &provider, rate.Provider)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -41,42 +44,91 @@ func TestGetRate_NotFound(t *testing.T) {
 	storage, _, mock := createRateMockStorage(t)
 
 	from, to := "USD", "EUR"
-	rows := sqlmock.NewRows([]string{"rate_value", "update_time"})
+	rows := sqlmock.NewRows([]string{"rate_value", "update_time", "provider"})
 
 	mock.ExpectPrepare(regexp.QuoteMeta(getRateSql)).
 		ExpectQuery().
 		WithArgs(from, to).
 		WillReturnRows(rows)
 
-	rate, err := storage.GetRate(from, to)
+	rate, err := storage.GetRate(context.Background(), from, to)
 	assert.Nil(t, rate)
 	assert.Error(t, err)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetRates_Success(t *testing.T) {
+	storage, _, mock := createRateMockStorage(t)
+
+	updateTime := time.Now()
+	rateValue := decimal.NewFromFloat(1.1)
+	rows := sqlmock.NewRows([]string{"from_currency", "to_currency", "rate_value", "update_time", "provider"}).
+		AddRow("EUR", "USD", rateValue, updateTime, nil)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(getRatesSql)).
+		ExpectQuery().
+		WithArgs(pq.Array([]string{"EUR", "USD"}), pq.Array([]string{"USD", "MXN"})).
+		WillReturnRows(rows)
+
+	rates, err := storage.GetRates(context.Background(), []model.CurrencyPair{{From: "EUR", To: "USD"}, {From: "USD", To: "MXN"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ExchangeRateDbo{
+		{FromCurrency: "EUR", ToCurrency: "USD", RateValue: &rateValue, UpdateTime: &updateTime},
+	}, rates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllRates_Success(t *testing.T) {
+	storage, _, mock := createRateMockStorage(t)
+
+	updateTime := time.Now()
+	firstRate, secondRate := decimal.NewFromFloat(1.1), decimal.NewFromFloat(18.5)
+	provider := "currency-api"
+	rows := sqlmock.NewRows([]string{"from_currency", "to_currency", "rate_value", "update_time", "provider"}).
+		AddRow("EUR", "USD", firstRate, updateTime, provider).
+		AddRow("EUR", "MXN", secondRate, updateTime, nil)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(getAllRatesSql)).
+		ExpectQuery().
+		WillReturnRows(rows)
+
+	rates, err := storage.GetAllRates(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ExchangeRateDbo{
+		{FromCurrency: "EUR", ToCurrency: "USD", RateValue: &firstRate, UpdateTime: &updateTime, Provider: &provider},
+		{FromCurrency: "EUR", ToCurrency: "MXN", RateValue: &secondRate, UpdateTime: &updateTime},
+	}, rates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func SetRateTx_Success(t *testing.T) {
 	storage, db, mock := createRateMockStorage(t)
-	rateValue, updateTime := decimal.NewFromFloat(123.45), time.Now()
+	rateValue, updateTime, provider := decimal.NewFromFloat(123.45), time.Now(), "currency-api"
 
 	dbo := model.ExchangeRateDbo{
 		FromCurrency: "USD",
 		ToCurrency:   "EUR",
 		RateValue:    &rateValue,
 		UpdateTime:   &updateTime,
+		Provider:     &provider,
 	}
 
 	mock.ExpectBegin()
 	mock.ExpectPrepare(regexp.QuoteMeta(setRateSql)).
 		ExpectExec().
-		WithArgs(dbo.FromCurrency, dbo.ToCurrency, dbo.RateValue, dbo.UpdateTime).
+		WithArgs(dbo.FromCurrency, dbo.ToCurrency, dbo.RateValue, dbo.UpdateTime, provider).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
 	tx, err := db.Begin()
 	require.NoError(t, err)
 
-	err = storage.SetRateTx(tx, &dbo)
+	err = storage.SetRateTx(context.Background(), tx, &dbo)
 	require.NoError(t, err)
 
 	err = tx.Commit()
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RateUpdateRequestedChannel is notified by getOrCreateRateUpdateSql with the id of the inserted update
+const RateUpdateRequestedChannel = "rate_update_requested"
+
+const (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+)
+
+// UpdateListener receives the notifications of requested updates on a dedicated connection.
+// The connection is re-established after failures, notifications sent while it was lost are missed
+type UpdateListener struct {
+	listener      *pq.Listener
+	notifications chan struct{}
+}
+
+func NewUpdateListener(connectionString string) *UpdateListener {
+	listener := pq.NewListener(connectionString, listenerMinReconnectInterval, listenerMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Println("Rate update listener:", err)
+			}
+		})
+
+	// Listen blocks until the connection is established, so the worker is not held while the database is down
+	go func() {
+		if err := listener.Listen(RateUpdateRequestedChannel); err != nil {
+			log.Println("Unable to listen to requested updates:", err)
+		}
+	}()
+
+	updateListener := &UpdateListener{
+		listener:      listener,
+		notifications: make(chan struct{}, 1),
+	}
+	go updateListener.forward(listener.Notify)
+
+	return updateListener
+}
+
+// forward passes the notifications until the source is closed, the payload is not needed by the worker.
+// Notifications are coalesced while one is pending, so a busy worker does not block the listener connection
+func (l *UpdateListener) forward(source <-chan *pq.Notification) {
+	for range source {
+		select {
+		case l.notifications <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Notifications receives a notification after updates are requested, one pending notification stands for all
+// updates requested since the worker last received it.
+// It is also notified after the connection is re-established, since notifications might have been missed
+func (l *UpdateListener) Notifications() <-chan struct{} {
+	return l.notifications
+}
+
+func (l *UpdateListener) Close() error {
+	return l.listener.Close()
+}
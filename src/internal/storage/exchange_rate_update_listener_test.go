@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForward_ShouldCoalesceNotificationsWhilePending(t *testing.T) {
+	listener := &UpdateListener{notifications: make(chan struct{}, 1)}
+	source := make(chan *pq.Notification, 3)
+	source <- &pq.Notification{Extra: "update-1"}
+	source <- &pq.Notification{Extra: "update-2"}
+	source <- nil
+	close(source)
+
+	// Returns only if the sends do not block on the pending notification
+	listener.forward(source)
+
+	assert.Len(t, listener.notifications, 1)
+}
+
+func TestForward_ShouldNotifyAgainAfterPendingNotificationReceived(t *testing.T) {
+	listener := &UpdateListener{notifications: make(chan struct{}, 1)}
+	source := make(chan *pq.Notification)
+	done := make(chan struct{})
+	go func() {
+		listener.forward(source)
+		close(done)
+	}()
+
+	source <- &pq.Notification{Extra: "update-1"}
+	<-listener.Notifications()
+	source <- &pq.Notification{Extra: "update-2"}
+	close(source)
+	<-done
+
+	assert.Len(t, listener.notifications, 1)
+}
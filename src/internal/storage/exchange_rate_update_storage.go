@@ -3,8 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/model"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type PostgresUpdateStorage struct {
@@ -12,11 +16,16 @@ type PostgresUpdateStorage struct {
 }
 
 type UpdateStorage interface {
-	GetOrCreateRateUpdate(updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error)
-	GetRateUpdate(updateId string) (*model.ExchangeRateUpdateDbo, error)
-	GetRatesForUpdate(fetchSize int) ([]model.ExchangeRateUpdateDbo, error)
-	UpdateRateTx(tx *sql.Tx, model *model.ExchangeRateUpdateDbo) error
-	SetError(updateId string) error
+	GetOrCreateRateUpdate(ctx context.Context, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error)
+	GetOrCreateRateUpdateTx(ctx context.Context, tx *sql.Tx, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error)
+	GetRateUpdate(ctx context.Context, updateId string) (*model.ExchangeRateUpdateDbo, error)
+	GetRatesForUpdate(ctx context.Context, workerId string, leaseDuration time.Duration, fetchSize int) ([]model.ExchangeRateUpdateDbo, error)
+	UpdateRateTx(ctx context.Context, tx *sql.Tx, model *model.ExchangeRateUpdateDbo) error
+	SetError(ctx context.Context, updateId string, workerId string, errorMessage string) error
+	ScheduleRetry(ctx context.Context, updateId string, workerId string, errorMessage string, nextAttemptAt time.Time) error
+	ReleaseClaim(ctx context.Context, updateId string, workerId string, nextAttemptAt time.Time) error
+	FailStuckUpdatesTx(ctx context.Context, tx *sql.Tx, maxAttempts int, maxAge time.Duration) (int, error)
+	RequeueExpiredLeasesTx(ctx context.Context, tx *sql.Tx) (int, error)
 }
 
 func NewUpdateStorage(db *sql.DB) UpdateStorage {
@@ -37,17 +46,33 @@ WITH new_update AS (
 SELECT id FROM exchange_rate_update 
 WHERE from_currency = $2 AND to_currency = $3 AND status = $4
 UNION ALL
-SELECT id FROM new_update
+SELECT new_update.id FROM new_update
+CROSS JOIN LATERAL (SELECT pg_notify('rate_update_requested', new_update.id)) AS notification
 `
 
-func (storage *PostgresUpdateStorage) GetOrCreateRateUpdate(updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
-	stmt, err := storage.db.PrepareContext(context.Background(), getOrCreateRateUpdateSql)
+// preparer is implemented by both *sql.DB and *sql.Tx
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// GetOrCreateRateUpdate returns the pending update of the pair or inserts the new one.
+// The inserted update is notified on RateUpdateRequestedChannel when the transaction commits
+func (storage *PostgresUpdateStorage) GetOrCreateRateUpdate(ctx context.Context, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
+	return getOrCreateRateUpdate(ctx, storage.db, updateId, from, to)
+}
+
+func (storage *PostgresUpdateStorage) GetOrCreateRateUpdateTx(ctx context.Context, tx *sql.Tx, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
+	return getOrCreateRateUpdate(ctx, tx, updateId, from, to)
+}
+
+func getOrCreateRateUpdate(ctx context.Context, db preparer, updateId string, from string, to string) (*model.ExchangeRateUpdateDbo, error) {
+	stmt, err := db.PrepareContext(ctx, getOrCreateRateUpdateSql)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.Background(), updateId, from, to, model.StatusUpdating)
+	rows, err := stmt.QueryContext(ctx, updateId, from, to, model.StatusUpdating)
 	if err != nil {
 		return nil, err
 	}
@@ -65,19 +90,19 @@ func (storage *PostgresUpdateStorage) GetOrCreateRateUpdate(updateId string, fro
 }
 
 const getRateUpdateSql = `
-SELECT from_currency, to_currency, status, rate_value, update_time 
+SELECT from_currency, to_currency, status, rate_value, update_time, created_at, attempts, error_message, provider
 FROM exchange_rate_update
 WHERE id = $1
 `
 
-func (storage *PostgresUpdateStorage) GetRateUpdate(updateId string) (*model.ExchangeRateUpdateDbo, error) {
-	stmt, err := storage.db.PrepareContext(context.Background(), getRateUpdateSql)
+func (storage *PostgresUpdateStorage) GetRateUpdate(ctx context.Context, updateId string) (*model.ExchangeRateUpdateDbo, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getRateUpdateSql)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.Background(), updateId)
+	rows, err := stmt.QueryContext(ctx, updateId)
 	if err != nil {
 		return nil, err
 	}
@@ -90,25 +115,46 @@ func (storage *PostgresUpdateStorage) GetRateUpdate(updateId string) (*model.Exc
 
 	update := model.ExchangeRateUpdateDbo{Id: updateId}
 
-	err = rows.Scan(&update.FromCurrency, &update.ToCurrency, &update.Status, &update.RateValue, &update.UpdateTime)
+	err = rows.Scan(
+		&update.FromCurrency,
+		&update.ToCurrency,
+		&update.Status,
+		&update.RateValue,
+		&update.UpdateTime,
+		&update.CreatedAt,
+		&update.Attempts,
+		&update.ErrorMessage,
+		&update.Provider)
 	return &update, err
 }
 
+// getRatesForUpdateSql claims unclaimed updates for the worker. Rows locked by another worker are skipped,
+// so concurrent workers never claim the same update
 const getRatesForUpdateSql = `
-SELECT id, from_currency, to_currency
-FROM exchange_rate_update
-WHERE status = $2
-LIMIT $1
+WITH claimed AS (
+	SELECT id
+	FROM exchange_rate_update
+	WHERE status = $2
+		AND claimed_by IS NULL
+		AND (next_attempt_at IS NULL OR next_attempt_at <= now() AT TIME ZONE 'utc')
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE exchange_rate_update
+SET claimed_by = $3, lease_expires_at = now() AT TIME ZONE 'utc' + $4 * INTERVAL '1 millisecond'
+FROM claimed
+WHERE exchange_rate_update.id = claimed.id
+RETURNING exchange_rate_update.id, from_currency, to_currency, attempts
 `
 
-func (storage *PostgresUpdateStorage) GetRatesForUpdate(fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
-	stmt, err := storage.db.PrepareContext(context.Background(), getRatesForUpdateSql)
+func (storage *PostgresUpdateStorage) GetRatesForUpdate(ctx context.Context, workerId string, leaseDuration time.Duration, fetchSize int) ([]model.ExchangeRateUpdateDbo, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getRatesForUpdateSql)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.Background(), fetchSize, model.StatusUpdating)
+	rows, err := stmt.QueryContext(ctx, fetchSize, model.StatusUpdating, workerId, leaseDuration.Milliseconds())
 	if err != nil {
 		return nil, err
 	}
@@ -117,10 +163,11 @@ func (storage *PostgresUpdateStorage) GetRatesForUpdate(fetchSize int) ([]model.
 	dbos := make([]model.ExchangeRateUpdateDbo, 0, fetchSize)
 	for rows.Next() {
 		update := model.ExchangeRateUpdateDbo{
-			Status: 0,
+			Status:    0,
+			ClaimedBy: workerId,
 		}
 
-		if err := rows.Scan(&update.Id, &update.FromCurrency, &update.ToCurrency); err != nil {
+		if err := rows.Scan(&update.Id, &update.FromCurrency, &update.ToCurrency, &update.Attempts); err != nil {
 			return nil, err
 		}
 
@@ -132,34 +179,206 @@ func (storage *PostgresUpdateStorage) GetRatesForUpdate(fetchSize int) ([]model.
 
 const updateRateSql = `
 UPDATE exchange_rate_update 
-SET rate_value = $2, update_time = $3, status = $4
-WHERE id = $1
+SET rate_value = $2, update_time = $3, status = $4, provider = $6, quotes = $7, spread = $8,
+	attempts = attempts + 1, error_message = NULL, claimed_by = NULL, lease_expires_at = NULL
+WHERE id = $1 AND claimed_by = $5 AND lease_expires_at > now() AT TIME ZONE 'utc'
 `
 
-func (storage *PostgresUpdateStorage) UpdateRateTx(tx *sql.Tx, model *model.ExchangeRateUpdateDbo) error {
-	stmt, err := tx.PrepareContext(context.Background(), updateRateSql)
+// UpdateRateTx writes the rate only while the lease of the worker in model.ClaimedBy is held, ErrLeaseLost otherwise
+func (storage *PostgresUpdateStorage) UpdateRateTx(ctx context.Context, tx *sql.Tx, model *model.ExchangeRateUpdateDbo) error {
+	quotes, err := marshalQuotes(model.Sources)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, updateRateSql)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(context.Background(), model.Id, model.RateValue, model.UpdateTime, model.Status)
-	return err
+	result, err := stmt.ExecContext(
+		ctx,
+		model.Id,
+		model.RateValue,
+		model.UpdateTime,
+		model.Status,
+		model.ClaimedBy,
+		model.Provider,
+		quotes,
+		model.Spread)
+	if err != nil {
+		return err
+	}
+
+	return checkLease(result)
 }
 
 const setErrorSql = `
 UPDATE exchange_rate_update
-SET status = $2
-WHERE id = $1
+SET status = $2, error_message = $3, attempts = attempts + 1, claimed_by = NULL, lease_expires_at = NULL
+WHERE id = $1 AND claimed_by = $4 AND lease_expires_at > now() AT TIME ZONE 'utc'
+`
+
+func (storage *PostgresUpdateStorage) SetError(ctx context.Context, updateId string, workerId string, errorMessage string) error {
+	stmt, err := storage.db.PrepareContext(ctx, setErrorSql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, updateId, model.StatusError, errorMessage, workerId)
+	if err != nil {
+		return err
+	}
+
+	return checkLease(result)
+}
+
+const scheduleRetrySql = `
+UPDATE exchange_rate_update
+SET error_message = $2, next_attempt_at = $3, attempts = attempts + 1, claimed_by = NULL, lease_expires_at = NULL
+WHERE id = $1 AND claimed_by = $4 AND lease_expires_at > now() AT TIME ZONE 'utc'
 `
 
-func (storage *PostgresUpdateStorage) SetError(updateId string) error {
-	stmt, err := storage.db.PrepareContext(context.Background(), setErrorSql)
+// ScheduleRetry keeps the update in the updating status and releases the claim, so it is picked again after nextAttemptAt
+func (storage *PostgresUpdateStorage) ScheduleRetry(ctx context.Context, updateId string, workerId string, errorMessage string, nextAttemptAt time.Time) error {
+	stmt, err := storage.db.PrepareContext(ctx, scheduleRetrySql)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(context.Background(), updateId, model.StatusError)
-	return err
+	result, err := stmt.ExecContext(ctx, updateId, errorMessage, nextAttemptAt, workerId)
+	if err != nil {
+		return err
+	}
+
+	return checkLease(result)
+}
+
+const releaseClaimSql = `
+UPDATE exchange_rate_update
+SET next_attempt_at = $2, claimed_by = NULL, lease_expires_at = NULL
+WHERE id = $1 AND claimed_by = $3 AND lease_expires_at > now() AT TIME ZONE 'utc'
+`
+
+// ReleaseClaim returns the update to the queue after nextAttemptAt without counting an attempt
+func (storage *PostgresUpdateStorage) ReleaseClaim(ctx context.Context, updateId string, workerId string, nextAttemptAt time.Time) error {
+	stmt, err := storage.db.PrepareContext(ctx, releaseClaimSql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, updateId, nextAttemptAt, workerId)
+	if err != nil {
+		return err
+	}
+
+	return checkLease(result)
+}
+
+const (
+	updateTimedOutMessage = "update was not completed in time"
+	leaseExpiredMessage   = "worker lease expired"
+)
+
+// failStuckUpdatesSql fails updates older than the max age and updates whose lease expired on the last attempt
+const failStuckUpdatesSql = `
+UPDATE exchange_rate_update
+SET status = $1,
+	error_message = CASE WHEN created_at <= now() AT TIME ZONE 'utc' - $4 * INTERVAL '1 millisecond' THEN $5 ELSE $6 END,
+	attempts = CASE WHEN claimed_by IS NULL THEN attempts ELSE attempts + 1 END,
+	claimed_by = NULL,
+	lease_expires_at = NULL
+WHERE status = $2 AND (
+	created_at <= now() AT TIME ZONE 'utc' - $4 * INTERVAL '1 millisecond'
+	OR (claimed_by IS NOT NULL AND lease_expires_at <= now() AT TIME ZONE 'utc' AND attempts + 1 >= $3)
+)
+`
+
+func (storage *PostgresUpdateStorage) FailStuckUpdatesTx(ctx context.Context, tx *sql.Tx, maxAttempts int, maxAge time.Duration) (int, error) {
+	stmt, err := tx.PrepareContext(ctx, failStuckUpdatesSql)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(
+		ctx,
+		model.StatusError,
+		model.StatusUpdating,
+		maxAttempts,
+		maxAge.Milliseconds(),
+		updateTimedOutMessage,
+		leaseExpiredMessage)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// requeueExpiredLeasesSql releases claims of crashed workers. The abandoned attempt is counted
+const requeueExpiredLeasesSql = `
+UPDATE exchange_rate_update
+SET claimed_by = NULL, lease_expires_at = NULL, attempts = attempts + 1, error_message = $2
+WHERE status = $1 AND claimed_by IS NOT NULL AND lease_expires_at <= now() AT TIME ZONE 'utc'
+`
+
+func (storage *PostgresUpdateStorage) RequeueExpiredLeasesTx(ctx context.Context, tx *sql.Tx) (int, error) {
+	stmt, err := tx.PrepareContext(ctx, requeueExpiredLeasesSql)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, model.StatusUpdating, leaseExpiredMessage)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+type providerQuoteJson struct {
+	Provider string          `json:"provider"`
+	Rate     decimal.Decimal `json:"rate"`
+	Rejected bool            `json:"rejected"`
+}
+
+// marshalQuotes returns nil for the rate of a single provider, so quotes column stays NULL
+func marshalQuotes(sources []model.ProviderQuote) (*string, error) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	quotes := make([]providerQuoteJson, 0, len(sources))
+	for _, source := range sources {
+		quotes = append(quotes, providerQuoteJson{Provider: source.Provider, Rate: source.Rate, Rejected: source.Rejected})
+	}
+
+	value, err := json.Marshal(quotes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := string(value)
+	return &result, nil
+}
+
+func checkLease(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return internal.ErrLeaseLost
+	}
+
+	return nil
 }
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"exchange-rates-service/src/internal"
 	"exchange-rates-service/src/internal/model"
 	"regexp"
 	"testing"
@@ -24,7 +26,7 @@ func TestGetOrCreateRateUpdate_ShouldSuccess(t *testing.T) {
 		WithArgs(updateId, from, to, model.StatusUpdating).
 		WillReturnRows(rows)
 
-	update, err := storage.GetOrCreateRateUpdate(updateId, from, to)
+	update, err := storage.GetOrCreateRateUpdate(context.Background(), updateId, from, to)
 
 	assert.NoError(t, err)
 	assert.Equal(t, updateId, update.Id)
@@ -36,6 +38,34 @@ func TestGetOrCreateRateUpdate_ShouldSuccess(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetOrCreateRateUpdate_ShouldNotifyListenedChannel(t *testing.T) {
+	assert.Contains(t, getOrCreateRateUpdateSql, "pg_notify('"+RateUpdateRequestedChannel+"'")
+}
+
+func TestGetOrCreateRateUpdateTx_ShouldSuccess(t *testing.T) {
+	storage, db, mock := createUpdateMockStorage(t)
+
+	updateId, from, to := "test-update-id", "USD", "EUR"
+	rows := sqlmock.NewRows([]string{"id"}).AddRow("existing-update-id")
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta(getOrCreateRateUpdateSql)).
+		ExpectQuery().
+		WithArgs(updateId, from, to, model.StatusUpdating).
+		WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	update, err := storage.GetOrCreateRateUpdateTx(context.Background(), tx, updateId, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, "existing-update-id", update.Id)
+
+	require.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetRateUpdate_Success(t *testing.T) {
 	storage, _, mock := createUpdateMockStorage(t)
 
@@ -44,15 +74,16 @@ func TestGetRateUpdate_Success(t *testing.T) {
 	updateTime := time.Now()
 	status := model.StatusDone
 
-	rows := sqlmock.NewRows([]string{"from_currency", "to_currency", "status", "rate_value", "update_time"}).
-		AddRow(from, to, status, rateValue, updateTime)
+	createdAt, errorMessage, provider := updateTime.Add(-time.Second), "previous error", "currency-api"
+	rows := sqlmock.NewRows([]string{"from_currency", "to_currency", "status", "rate_value", "update_time", "created_at", "attempts", "error_message", "provider"}).
+		AddRow(from, to, status, rateValue, updateTime, createdAt, 2, errorMessage, provider)
 
 	mock.ExpectPrepare(regexp.QuoteMeta(getRateUpdateSql)).
 		ExpectQuery().
 		WithArgs(updateId).
 		WillReturnRows(rows)
 
-	update, err := storage.GetRateUpdate(updateId)
+	update, err := storage.GetRateUpdate(context.Background(), updateId)
 
 	assert.NoError(t, err)
 	assert.Equal(t, updateId, update.Id)
@@ -61,6 +92,10 @@ func TestGetRateUpdate_Success(t *testing.T) {
 	assert.Equal(t, status, update.Status)
 	assert.Equal(t, &rateValue, update.RateValue)
 	assert.Equal(t, &updateTime, update.UpdateTime)
+	assert.Equal(t, &createdAt, update.CreatedAt)
+	assert.Equal(t, 2, update.Attempts)
+	assert.Equal(t, &errorMessage, update.ErrorMessage)
+	assert.Equal(t, &provider, update.Provider)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -69,14 +104,14 @@ func TestGetRateUpdate_NotFound(t *testing.T) {
 	storage, _, mock := createUpdateMockStorage(t)
 
 	updateId := "non-existent-id"
-	rows := sqlmock.NewRows([]string{"from_currency", "to_currency", "status", "rate_value", "update_time"})
+	rows := sqlmock.NewRows([]string{"from_currency", "to_currency", "status", "rate_value", "update_time", "created_at", "attempts", "error_message", "provider"})
 
 	mock.ExpectPrepare(regexp.QuoteMeta(getRateUpdateSql)).
 		ExpectQuery().
 		WithArgs(updateId).
 		WillReturnRows(rows)
 
-	update, err := storage.GetRateUpdate(updateId)
+	update, err := storage.GetRateUpdate(context.Background(), updateId)
 
 	assert.Nil(t, update)
 	assert.Error(t, err)
@@ -94,22 +129,22 @@ func TestGetRatesForUpdate_Success(t *testing.T) {
 	storage := NewUpdateStorage(db)
 
 	fetchSize := 10
-	rows := sqlmock.NewRows([]string{"id", "from_currency", "to_currency"}).
-		AddRow("update-1", "USD", "EUR").
-		AddRow("update-2", "EUR", "USD").
-		AddRow("update-3", "EUR", "MXN")
+	rows := sqlmock.NewRows([]string{"id", "from_currency", "to_currency", "attempts"}).
+		AddRow("update-1", "USD", "EUR", 0).
+		AddRow("update-2", "EUR", "USD", 2).
+		AddRow("update-3", "EUR", "MXN", 0)
 
 	mock.ExpectPrepare(regexp.QuoteMeta(getRatesForUpdateSql)).
 		ExpectQuery().
-		WithArgs(fetchSize, model.StatusUpdating).
+		WithArgs(fetchSize, model.StatusUpdating, "worker-1", int64(30000)).
 		WillReturnRows(rows)
 
-	updates, err := storage.GetRatesForUpdate(fetchSize)
+	updates, err := storage.GetRatesForUpdate(context.Background(), "worker-1", 30*time.Second, fetchSize)
 	assert.NoError(t, err)
 	assert.Len(t, updates, 3)
-	assert.Equal(t, updates[0], model.ExchangeRateUpdateDbo{Id: "update-1", FromCurrency: "USD", ToCurrency: "EUR"})
-	assert.Equal(t, updates[1], model.ExchangeRateUpdateDbo{Id: "update-2", FromCurrency: "EUR", ToCurrency: "USD"})
-	assert.Equal(t, updates[2], model.ExchangeRateUpdateDbo{Id: "update-3", FromCurrency: "EUR", ToCurrency: "MXN"})
+	assert.Equal(t, updates[0], model.ExchangeRateUpdateDbo{Id: "update-1", FromCurrency: "USD", ToCurrency: "EUR", ClaimedBy: "worker-1"})
+	assert.Equal(t, updates[1], model.ExchangeRateUpdateDbo{Id: "update-2", FromCurrency: "EUR", ToCurrency: "USD", Attempts: 2, ClaimedBy: "worker-1"})
+	assert.Equal(t, updates[2], model.ExchangeRateUpdateDbo{Id: "update-3", FromCurrency: "EUR", ToCurrency: "MXN", ClaimedBy: "worker-1"})
 
 	for _, update := range updates {
 		assert.Equal(t, model.StatusUpdating, update.Status)
@@ -122,14 +157,14 @@ func TestGetRatesForUpdate_EmptyResult(t *testing.T) {
 	storage, _, mock := createUpdateMockStorage(t)
 
 	fetchSize := 10
-	rows := sqlmock.NewRows([]string{"id", "from_currency", "to_currency"})
+	rows := sqlmock.NewRows([]string{"id", "from_currency", "to_currency", "attempts"})
 
 	mock.ExpectPrepare(regexp.QuoteMeta(getRatesForUpdateSql)).
 		ExpectQuery().
-		WithArgs(fetchSize, model.StatusUpdating).
+		WithArgs(fetchSize, model.StatusUpdating, "worker-1", int64(30000)).
 		WillReturnRows(rows)
 
-	updates, err := storage.GetRatesForUpdate(fetchSize)
+	updates, err := storage.GetRatesForUpdate(context.Background(), "worker-1", 30*time.Second, fetchSize)
 
 	assert.NoError(t, err)
 	assert.Len(t, updates, 0)
@@ -141,26 +176,36 @@ func TestUpdateRateTx_Success(t *testing.T) {
 	storage, db, mock := createUpdateMockStorage(t)
 
 	updateId, rateValue := "test-update-id", decimal.NewFromFloat(1.2345)
-	updateTime, status := time.Now(), model.StatusDone
+	updateTime, status, provider := time.Now(), model.StatusDone, "consensus"
+	spread := decimal.RequireFromString("0.01")
 
 	updateDbo := model.ExchangeRateUpdateDbo{
 		Id:         updateId,
 		RateValue:  &rateValue,
 		UpdateTime: &updateTime,
 		Status:     status,
+		ClaimedBy:  "worker-1",
+		Provider:   &provider,
+		Sources: []model.ProviderQuote{
+			{Provider: "currency-api", Rate: decimal.RequireFromString("1.2345")},
+			{Provider: "exchangeratesapi.io", Rate: decimal.RequireFromString("1.5"), Rejected: true},
+		},
+		Spread: &spread,
 	}
+	quotes := `[{"provider":"currency-api","rate":"1.2345","rejected":false},` +
+		`{"provider":"exchangeratesapi.io","rate":"1.5","rejected":true}]`
 
 	mock.ExpectBegin()
 	mock.ExpectPrepare(regexp.QuoteMeta(updateRateSql)).
 		ExpectExec().
-		WithArgs(updateId, &rateValue, &updateTime, status).
+		WithArgs(updateId, &rateValue, &updateTime, status, "worker-1", provider, quotes, &spread).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
 	tx, err := db.Begin()
 	require.NoError(t, err)
 
-	err = storage.UpdateRateTx(tx, &updateDbo)
+	err = storage.UpdateRateTx(context.Background(), tx, &updateDbo)
 	require.NoError(t, err)
 
 	err = tx.Commit()
@@ -170,22 +215,115 @@ func TestUpdateRateTx_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpdateRateTx_ShouldReturnLeaseLostWhenLeaseIsNotHeld(t *testing.T) {
+	storage, db, mock := createUpdateMockStorage(t)
+
+	rateValue, updateTime, provider := decimal.NewFromFloat(1.2345), time.Now(), "currency-api"
+	updateDbo := model.ExchangeRateUpdateDbo{
+		Id:         "test-update-id",
+		RateValue:  &rateValue,
+		UpdateTime: &updateTime,
+		Status:     model.StatusDone,
+		ClaimedBy:  "worker-1",
+		Provider:   &provider,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta(updateRateSql)).
+		ExpectExec().
+		WithArgs(updateDbo.Id, &rateValue, &updateTime, model.StatusDone, "worker-1", provider, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	err = storage.UpdateRateTx(context.Background(), tx, &updateDbo)
+	assert.ErrorIs(t, err, internal.ErrLeaseLost)
+
+	require.NoError(t, tx.Rollback())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSetError_Success(t *testing.T) {
 	storage, _, mock := createUpdateMockStorage(t)
 
-	updateId := "test-update-id"
+	updateId, errorMessage := "test-update-id", "api error"
 
 	mock.ExpectPrepare(regexp.QuoteMeta(setErrorSql)).
 		ExpectExec().
-		WithArgs(updateId, model.StatusError).
+		WithArgs(updateId, model.StatusError, errorMessage, "worker-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := storage.SetError(context.Background(), updateId, "worker-1", errorMessage)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleRetry_Success(t *testing.T) {
+	storage, _, mock := createUpdateMockStorage(t)
+
+	updateId, errorMessage := "test-update-id", "api error"
+	nextAttemptAt := time.Now().UTC().Add(time.Minute)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(scheduleRetrySql)).
+		ExpectExec().
+		WithArgs(updateId, errorMessage, nextAttemptAt, "worker-1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err := storage.SetError(updateId)
+	err := storage.ScheduleRetry(context.Background(), updateId, "worker-1", errorMessage, nextAttemptAt)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestReleaseClaim_ShouldReturnLeaseLostWhenNotClaimed(t *testing.T) {
+	storage, _, mock := createUpdateMockStorage(t)
+
+	updateId := "test-update-id"
+	nextAttemptAt := time.Now().UTC().Add(time.Minute)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(releaseClaimSql)).
+		ExpectExec().
+		WithArgs(updateId, nextAttemptAt, "worker-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := storage.ReleaseClaim(context.Background(), updateId, "worker-1", nextAttemptAt)
+
+	assert.ErrorIs(t, err, internal.ErrLeaseLost)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReclaimStuckUpdatesTx_Success(t *testing.T) {
+	storage, db, mock := createUpdateMockStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta(failStuckUpdatesSql)).
+		ExpectExec().
+		WithArgs(model.StatusError, model.StatusUpdating, 5, int64(3600000), updateTimedOutMessage, leaseExpiredMessage).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare(regexp.QuoteMeta(requeueExpiredLeasesSql)).
+		ExpectExec().
+		WithArgs(model.StatusUpdating, leaseExpiredMessage).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	failed, err := storage.FailStuckUpdatesTx(context.Background(), tx, 5, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, failed)
+
+	requeued, err := storage.RequeueExpiredLeasesTx(context.Background(), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requeued)
+
+	require.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func createUpdateMockStorage(t *testing.T) (UpdateStorage, *sql.DB, sqlmock.Sqlmock) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type PostgresProviderUsageStorage struct {
+	db *sql.DB
+}
+
+type ProviderUsageStorage interface {
+	ReserveRequest(ctx context.Context, provider string, period time.Time, maxRequests int) (int, bool, error)
+	GetUsage(ctx context.Context, provider string, period time.Time) (int, error)
+}
+
+func NewProviderUsageStorage(db *sql.DB) ProviderUsageStorage {
+	return &PostgresProviderUsageStorage{db: db}
+}
+
+// reserveRequestSql counts the request only while the period usage is below the max requests,
+// so concurrent workers cannot exceed it
+const reserveRequestSql = `
+INSERT INTO provider_usage (provider, period, requests)
+VALUES ($1, $2, 1)
+ON CONFLICT (provider, period) DO UPDATE SET requests = provider_usage.requests + 1
+WHERE provider_usage.requests < $3
+RETURNING requests
+`
+
+// ReserveRequest counts one request of the provider in the period. Returns false when max requests are already used
+func (storage *PostgresProviderUsageStorage) ReserveRequest(ctx context.Context, provider string, period time.Time, maxRequests int) (int, bool, error) {
+	stmt, err := storage.db.PrepareContext(ctx, reserveRequestSql)
+	if err != nil {
+		return 0, false, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, provider, period, maxRequests)
+	if err != nil {
+		return 0, false, err
+	}
+	defer rows.Close()
+
+	hasData := rows.Next()
+	if !hasData {
+		return maxRequests, false, rows.Err()
+	}
+
+	var requests int
+	err = rows.Scan(&requests)
+	return requests, err == nil, err
+}
+
+const getUsageSql = `
+SELECT requests FROM provider_usage
+WHERE provider = $1 AND period = $2
+`
+
+func (storage *PostgresProviderUsageStorage) GetUsage(ctx context.Context, provider string, period time.Time) (int, error) {
+	stmt, err := storage.db.PrepareContext(ctx, getUsageSql)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, provider, period)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	hasData := rows.Next()
+	if !hasData {
+		return 0, rows.Err()
+	}
+
+	var requests int
+	err = rows.Scan(&requests)
+	return requests, err
+}
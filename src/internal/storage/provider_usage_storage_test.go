@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveRequest_Success(t *testing.T) {
+	storage, _, mock := createProviderUsageMockStorage(t)
+
+	period := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare(regexp.QuoteMeta(reserveRequestSql)).
+		ExpectQuery().
+		WithArgs("exchangeratesapi.io", period, 950).
+		WillReturnRows(sqlmock.NewRows([]string{"requests"}).AddRow(12))
+
+	requests, reserved, err := storage.ReserveRequest(context.Background(), "exchangeratesapi.io", period, 950)
+
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, 12, requests)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveRequest_ShouldNotReserveWhenMaxRequestsUsed(t *testing.T) {
+	storage, _, mock := createProviderUsageMockStorage(t)
+
+	period := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare(regexp.QuoteMeta(reserveRequestSql)).
+		ExpectQuery().
+		WithArgs("exchangeratesapi.io", period, 950).
+		WillReturnRows(sqlmock.NewRows([]string{"requests"}))
+
+	requests, reserved, err := storage.ReserveRequest(context.Background(), "exchangeratesapi.io", period, 950)
+
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, 950, requests)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsage_ShouldReturnZeroWithoutRequests(t *testing.T) {
+	storage, _, mock := createProviderUsageMockStorage(t)
+
+	period := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare(regexp.QuoteMeta(getUsageSql)).
+		ExpectQuery().
+		WithArgs("exchangeratesapi.io", period).
+		WillReturnRows(sqlmock.NewRows([]string{"requests"}))
+
+	requests, err := storage.GetUsage(context.Background(), "exchangeratesapi.io", period)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requests)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func createProviderUsageMockStorage(t *testing.T) (ProviderUsageStorage, *sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	storage := NewProviderUsageStorage(db)
+	return storage, db, mock
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS exchange_rate_history_range_index;
+
+DROP TABLE IF EXISTS exchange_rate_history;
\ No newline at end of file
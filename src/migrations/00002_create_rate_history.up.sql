@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS exchange_rate_history
+(
+	id BIGSERIAL PRIMARY KEY,
+	update_id TEXT NOT NULL,
+	from_currency TEXT NOT NULL,
+	to_currency TEXT NOT NULL,
+	rate_value DECIMAL(18, 6) NOT NULL,
+	update_time TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS exchange_rate_history_range_index
+ON exchange_rate_history(from_currency, to_currency, update_time, id);
\ No newline at end of file
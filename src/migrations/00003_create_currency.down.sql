@@ -0,0 +1 @@
+DROP TABLE IF EXISTS currency;
\ No newline at end of file
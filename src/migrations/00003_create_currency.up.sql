@@ -0,0 +1,167 @@
+CREATE TABLE IF NOT EXISTS currency
+(
+	code TEXT NOT NULL PRIMARY KEY,
+	numeric_code TEXT NOT NULL,
+	minor_units INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	active BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+INSERT INTO currency(code, numeric_code, minor_units, name, active)
+VALUES
+	('AED', '784', 2, 'UAE Dirham', FALSE),
+	('AFN', '971', 2, 'Afghani', FALSE),
+	('ALL', '008', 2, 'Lek', FALSE),
+	('AMD', '051', 2, 'Armenian Dram', FALSE),
+	('ANG', '532', 2, 'Netherlands Antillean Guilder', FALSE),
+	('AOA', '973', 2, 'Kwanza', FALSE),
+	('ARS', '032', 2, 'Argentine Peso', FALSE),
+	('AUD', '036', 2, 'Australian Dollar', FALSE),
+	('AWG', '533', 2, 'Aruban Florin', FALSE),
+	('AZN', '944', 2, 'Azerbaijan Manat', FALSE),
+	('BAM', '977', 2, 'Convertible Mark', FALSE),
+	('BBD', '052', 2, 'Barbados Dollar', FALSE),
+	('BDT', '050', 2, 'Taka', FALSE),
+	('BGN', '975', 2, 'Bulgarian Lev', FALSE),
+	('BHD', '048', 3, 'Bahraini Dinar', FALSE),
+	('BIF', '108', 0, 'Burundi Franc', FALSE),
+	('BMD', '060', 2, 'Bermudian Dollar', FALSE),
+	('BND', '096', 2, 'Brunei Dollar', FALSE),
+	('BOB', '068', 2, 'Boliviano', FALSE),
+	('BRL', '986', 2, 'Brazilian Real', FALSE),
+	('BSD', '044', 2, 'Bahamian Dollar', FALSE),
+	('BTN', '064', 2, 'Ngultrum', FALSE),
+	('BWP', '072', 2, 'Pula', FALSE),
+	('BYN', '933', 2, 'Belarusian Ruble', FALSE),
+	('BZD', '084', 2, 'Belize Dollar', FALSE),
+	('CAD', '124', 2, 'Canadian Dollar', FALSE),
+	('CDF', '976', 2, 'Congolese Franc', FALSE),
+	('CHF', '756', 2, 'Swiss Franc', FALSE),
+	('CLP', '152', 0, 'Chilean Peso', FALSE),
+	('CNY', '156', 2, 'Yuan Renminbi', FALSE),
+	('COP', '170', 2, 'Colombian Peso', FALSE),
+	('CRC', '188', 2, 'Costa Rican Colon', FALSE),
+	('CUP', '192', 2, 'Cuban Peso', FALSE),
+	('CVE', '132', 2, 'Cabo Verde Escudo', FALSE),
+	('CZK', '203', 2, 'Czech Koruna', FALSE),
+	('DJF', '262', 0, 'Djibouti Franc', FALSE),
+	('DKK', '208', 2, 'Danish Krone', FALSE),
+	('DOP', '214', 2, 'Dominican Peso', FALSE),
+	('DZD', '012', 2, 'Algerian Dinar', FALSE),
+	('EGP', '818', 2, 'Egyptian Pound', FALSE),
+	('ERN', '232', 2, 'Nakfa', FALSE),
+	('ETB', '230', 2, 'Ethiopian Birr', FALSE),
+	('EUR', '978', 2, 'Euro', TRUE),
+	('FJD', '242', 2, 'Fiji Dollar', FALSE),
+	('FKP', '238', 2, 'Falkland Islands Pound', FALSE),
+	('GBP', '826', 2, 'Pound Sterling', FALSE),
+	('GEL', '981', 2, 'Lari', FALSE),
+	('GHS', '936', 2, 'Ghana Cedi', FALSE),
+	('GIP', '292', 2, 'Gibraltar Pound', FALSE),
+	('GMD', '270', 2, 'Dalasi', FALSE),
+	('GNF', '324', 0, 'Guinean Franc', FALSE),
+	('GTQ', '320', 2, 'Quetzal', FALSE),
+	('GYD', '328', 2, 'Guyana Dollar', FALSE),
+	('HKD', '344', 2, 'Hong Kong Dollar', FALSE),
+	('HNL', '340', 2, 'Lempira', FALSE),
+	('HTG', '332', 2, 'Gourde', FALSE),
+	('HUF', '348', 2, 'Forint', FALSE),
+	('IDR', '360', 2, 'Rupiah', FALSE),
+	('ILS', '376', 2, 'New Israeli Sheqel', FALSE),
+	('INR', '356', 2, 'Indian Rupee', FALSE),
+	('IQD', '368', 3, 'Iraqi Dinar', FALSE),
+	('IRR', '364', 2, 'Iranian Rial', FALSE),
+	('ISK', '352', 0, 'Iceland Krona', FALSE),
+	('JMD', '388', 2, 'Jamaican Dollar', FALSE),
+	('JOD', '400', 3, 'Jordanian Dinar', FALSE),
+	('JPY', '392', 0, 'Yen', FALSE),
+	('KES', '404', 2, 'Kenyan Shilling', FALSE),
+	('KGS', '417', 2, 'Som', FALSE),
+	('KHR', '116', 2, 'Riel', FALSE),
+	('KMF', '174', 0, 'Comorian Franc', FALSE),
+	('KPW', '408', 2, 'North Korean Won', FALSE),
+	('KRW', '410', 0, 'Won', FALSE),
+	('KWD', '414', 3, 'Kuwaiti Dinar', FALSE),
+	('KYD', '136', 2, 'Cayman Islands Dollar', FALSE),
+	('KZT', '398', 2, 'Tenge', FALSE),
+	('LAK', '418', 2, 'Lao Kip', FALSE),
+	('LBP', '422', 2, 'Lebanese Pound', FALSE),
+	('LKR', '144', 2, 'Sri Lanka Rupee', FALSE),
+	('LRD', '430', 2, 'Liberian Dollar', FALSE),
+	('LSL', '426', 2, 'Loti', FALSE),
+	('LYD', '434', 3, 'Libyan Dinar', FALSE),
+	('MAD', '504', 2, 'Moroccan Dirham', FALSE),
+	('MDL', '498', 2, 'Moldovan Leu', FALSE),
+	('MGA', '969', 2, 'Malagasy Ariary', FALSE),
+	('MKD', '807', 2, 'Denar', FALSE),
+	('MMK', '104', 2, 'Kyat', FALSE),
+	('MNT', '496', 2, 'Tugrik', FALSE),
+	('MOP', '446', 2, 'Pataca', FALSE),
+	('MRU', '929', 2, 'Ouguiya', FALSE),
+	('MUR', '480', 2, 'Mauritius Rupee', FALSE),
+	('MVR', '462', 2, 'Rufiyaa', FALSE),
+	('MWK', '454', 2, 'Malawi Kwacha', FALSE),
+	('MXN', '484', 2, 'Mexican Peso', TRUE),
+	('MYR', '458', 2, 'Malaysian Ringgit', FALSE),
+	('MZN', '943', 2, 'Mozambique Metical', FALSE),
+	('NAD', '516', 2, 'Namibia Dollar', FALSE),
+	('NGN', '566', 2, 'Naira', FALSE),
+	('NIO', '558', 2, 'Cordoba Oro', FALSE),
+	('NOK', '578', 2, 'Norwegian Krone', FALSE),
+	('NPR', '524', 2, 'Nepalese Rupee', FALSE),
+	('NZD', '554', 2, 'New Zealand Dollar', FALSE),
+	('OMR', '512', 3, 'Rial Omani', FALSE),
+	('PAB', '590', 2, 'Balboa', FALSE),
+	('PEN', '604', 2, 'Sol', FALSE),
+	('PGK', '598', 2, 'Kina', FALSE),
+	('PHP', '608', 2, 'Philippine Peso', FALSE),
+	('PKR', '586', 2, 'Pakistan Rupee', FALSE),
+	('PLN', '985', 2, 'Zloty', FALSE),
+	('PYG', '600', 0, 'Guarani', FALSE),
+	('QAR', '634', 2, 'Qatari Rial', FALSE),
+	('RON', '946', 2, 'Romanian Leu', FALSE),
+	('RSD', '941', 2, 'Serbian Dinar', FALSE),
+	('RUB', '643', 2, 'Russian Ruble', FALSE),
+	('RWF', '646', 0, 'Rwanda Franc', FALSE),
+	('SAR', '682', 2, 'Saudi Riyal', FALSE),
+	('SBD', '090', 2, 'Solomon Islands Dollar', FALSE),
+	('SCR', '690', 2, 'Seychelles Rupee', FALSE),
+	('SDG', '938', 2, 'Sudanese Pound', FALSE),
+	('SEK', '752', 2, 'Swedish Krona', FALSE),
+	('SGD', '702', 2, 'Singapore Dollar', FALSE),
+	('SHP', '654', 2, 'Saint Helena Pound', FALSE),
+	('SLE', '925', 2, 'Leone', FALSE),
+	('SOS', '706', 2, 'Somali Shilling', FALSE),
+	('SRD', '968', 2, 'Surinam Dollar', FALSE),
+	('SSP', '728', 2, 'South Sudanese Pound', FALSE),
+	('STN', '930', 2, 'Dobra', FALSE),
+	('SVC', '222', 2, 'El Salvador Colon', FALSE),
+	('SYP', '760', 2, 'Syrian Pound', FALSE),
+	('SZL', '748', 2, 'Lilangeni', FALSE),
+	('THB', '764', 2, 'Baht', FALSE),
+	('TJS', '972', 2, 'Somoni', FALSE),
+	('TMT', '934', 2, 'Turkmenistan New Manat', FALSE),
+	('TND', '788', 3, 'Tunisian Dinar', FALSE),
+	('TOP', '776', 2, 'Pa''anga', FALSE),
+	('TRY', '949', 2, 'Turkish Lira', FALSE),
+	('TTD', '780', 2, 'Trinidad and Tobago Dollar', FALSE),
+	('TWD', '901', 2, 'New Taiwan Dollar', FALSE),
+	('TZS', '834', 2, 'Tanzanian Shilling', FALSE),
+	('UAH', '980', 2, 'Hryvnia', FALSE),
+	('UGX', '800', 0, 'Uganda Shilling', FALSE),
+	('USD', '840', 2, 'US Dollar', TRUE),
+	('UYU', '858', 2, 'Peso Uruguayo', FALSE),
+	('UZS', '860', 2, 'Uzbekistan Sum', FALSE),
+	('VES', '928', 2, 'Bolivar Soberano', FALSE),
+	('VND', '704', 0, 'Dong', FALSE),
+	('VUV', '548', 0, 'Vatu', FALSE),
+	('WST', '882', 2, 'Tala', FALSE),
+	('XAF', '950', 0, 'CFA Franc BEAC', FALSE),
+	('XCD', '951', 2, 'East Caribbean Dollar', FALSE),
+	('XOF', '952', 0, 'CFA Franc BCEAO', FALSE),
+	('XPF', '953', 0, 'CFP Franc', FALSE),
+	('YER', '886', 2, 'Yemeni Rial', FALSE),
+	('ZAR', '710', 2, 'Rand', FALSE),
+	('ZMW', '967', 2, 'Zambian Kwacha', FALSE),
+	('ZWG', '924', 2, 'Zimbabwe Gold', FALSE)
+ON CONFLICT(code) DO NOTHING;
\ No newline at end of file
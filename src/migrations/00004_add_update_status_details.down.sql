@@ -0,0 +1,4 @@
+ALTER TABLE exchange_rate_update
+	DROP COLUMN IF EXISTS created_at,
+	DROP COLUMN IF EXISTS attempts,
+	DROP COLUMN IF EXISTS error_message;
\ No newline at end of file
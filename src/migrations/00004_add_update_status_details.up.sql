@@ -0,0 +1,4 @@
+ALTER TABLE exchange_rate_update
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+	ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS error_message TEXT;
\ No newline at end of file
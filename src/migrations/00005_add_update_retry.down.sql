@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS exchange_rate_update_next_attempt_index;
+
+ALTER TABLE exchange_rate_update
+	DROP COLUMN IF EXISTS next_attempt_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE exchange_rate_update
+	ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS exchange_rate_update_next_attempt_index
+ON exchange_rate_update(status, next_attempt_at);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE exchange_rate_update
+	DROP COLUMN IF EXISTS claimed_by,
+	DROP COLUMN IF EXISTS lease_expires_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE exchange_rate_update
+	ADD COLUMN IF NOT EXISTS claimed_by TEXT,
+	ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE exchange_rate_history
+	DROP COLUMN IF EXISTS provider;
+
+ALTER TABLE exchange_rate
+	DROP COLUMN IF EXISTS provider;
+
+ALTER TABLE exchange_rate_update
+	DROP COLUMN IF EXISTS provider;
\ No newline at end of file
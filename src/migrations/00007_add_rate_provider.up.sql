@@ -0,0 +1,8 @@
+ALTER TABLE exchange_rate_update
+	ADD COLUMN IF NOT EXISTS provider TEXT;
+
+ALTER TABLE exchange_rate
+	ADD COLUMN IF NOT EXISTS provider TEXT;
+
+ALTER TABLE exchange_rate_history
+	ADD COLUMN IF NOT EXISTS provider TEXT;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE exchange_rate_update
+	DROP COLUMN IF EXISTS quotes,
+	DROP COLUMN IF EXISTS spread;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE exchange_rate_update
+	ADD COLUMN IF NOT EXISTS quotes JSONB,
+	ADD COLUMN IF NOT EXISTS spread DECIMAL(18, 6);
\ No newline at end of file
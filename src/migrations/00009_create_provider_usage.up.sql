@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS provider_usage
+(
+	provider TEXT NOT NULL,
+	period DATE NOT NULL,
+	requests INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (provider, period)
+);
\ No newline at end of file